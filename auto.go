@@ -0,0 +1,182 @@
+package mcstatusgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// StatusProtocol identifies a protocol used to request information from a server, such as which implementation of the status protocol answered a request.
+type StatusProtocol string
+
+const (
+	// ProtocolStatus identifies the current implementation of the status protocol.
+	ProtocolStatus StatusProtocol = "status"
+	// ProtocolStatusLegacy identifies the legacy implementation of the status protocol.
+	ProtocolStatusLegacy StatusProtocol = "legacy status"
+	// ProtocolStatusBeta identifies the beta implementation of the status protocol.
+	ProtocolStatusBeta StatusProtocol = "beta status"
+	// ProtocolBasicQuery identifies the basic query protocol.
+	ProtocolBasicQuery StatusProtocol = "basic query"
+	// ProtocolFullQuery identifies the full query protocol.
+	ProtocolFullQuery StatusProtocol = "full query"
+)
+
+// StatusAutoResponse contains the information shared by every implementation of the status protocol.
+type StatusAutoResponse struct {
+	// Protocol contains the implementation of the status protocol that answered the request.
+	Protocol StatusProtocol
+
+	// IP contains the server's IP.
+	IP string
+
+	// Port contains the server's port used for communication.
+	Port uint16
+
+	// Latency contains the duration of time waited for the response.
+	Latency time.Duration
+
+	// Description contains the description in the format sent by the answering protocol.
+	//
+	// For ProtocolStatus, this is a pretty-print JSON string. Otherwise, it contains the MOTD of the server.
+	Description string
+
+	Version struct {
+		// Name contains the version of Minecraft running on the server.
+		//
+		// Empty for ProtocolStatusBeta.
+		Name string
+
+		// Protocol contains the protocol version used in the request or that should be used when connecting to the server.
+		//
+		// Zero for ProtocolStatusBeta.
+		Protocol int
+	}
+
+	Players struct {
+		// Max contains the maximum number of players the server supports.
+		Max int
+
+		// Online contains the current number of players on the server.
+		Online int
+	}
+
+	// Status contains the full response when Protocol is ProtocolStatus.
+	Status *StatusResponse
+
+	// StatusLegacy contains the full response when Protocol is ProtocolStatusLegacy.
+	StatusLegacy *StatusLegacyResponse
+
+	// StatusBeta contains the full response when Protocol is ProtocolStatusBeta.
+	StatusBeta *StatusBetaResponse
+}
+
+// StatusAuto requests basic server information from a Minecraft server without knowing which implementation of the status protocol it supports.
+//
+// Status is tried first, falling back to StatusLegacy and then StatusBeta when the server doesn't answer with a valid response.
+// Protocol-level failures such as the connection being reset mid-handshake, ErrServerRejectedStatus, or ErrInvalidSizeInfo all trigger the fallback.
+// Dial errors, invalid arguments (ErrInvalidArgument and ErrInvalidHostname), ErrImplausiblePlayerCount, and errors after the context given with WithContext is done are returned immediately without trying the other implementations.
+// The Protocol field of the response notes which implementation answered.
+//
+// If a valid response is received, a StatusAutoResponse is returned.
+// If every implementation fails, the error from Status is returned.
+func StatusAuto(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (StatusAutoResponse, error) {
+	ctx := newOptions(opts).context()
+
+	status, statusErr := Status(server, port, initialConnectionTimeout, ioTimeout, opts...)
+	if statusErr == nil {
+		return autoFromStatus(status), nil
+	}
+	if !shouldFallback(ctx, statusErr) {
+		return StatusAutoResponse{}, statusErr
+	}
+
+	statusLegacy, err := StatusLegacy(server, port, initialConnectionTimeout, ioTimeout, opts...)
+	if err == nil {
+		return autoFromStatusLegacy(statusLegacy), nil
+	}
+	if !shouldFallback(ctx, err) {
+		return StatusAutoResponse{}, err
+	}
+
+	statusBeta, err := StatusBeta(server, port, initialConnectionTimeout, ioTimeout, opts...)
+	if err == nil {
+		return autoFromStatusBeta(statusBeta), nil
+	}
+	if !shouldFallback(ctx, err) {
+		return StatusAutoResponse{}, err
+	}
+
+	return StatusAutoResponse{}, statusErr
+}
+
+// shouldFallback reports whether err was caused by the server not supporting the attempted protocol rather than by the connection.
+//
+// Servers answer an unsupported implementation in many ways (kick packets, resets, garbage, or silence), so every error is treated as a protocol-level failure
+// besides dial errors, invalid arguments, and errors after ctx is done, which every implementation would fail with.
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrInvalidArgument) || errors.Is(err, ErrInvalidHostname) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+
+	var playerCountErr ErrImplausiblePlayerCount
+	return !errors.As(err, &playerCountErr)
+}
+
+// autoFromStatus packages status into a StatusAutoResponse.
+func autoFromStatus(status StatusResponse) StatusAutoResponse {
+	auto := StatusAutoResponse{}
+	auto.Protocol = ProtocolStatus
+	auto.IP = status.IP
+	auto.Port = status.Port
+	auto.Latency = status.Latency
+	auto.Description = status.Description
+	auto.Version.Name = status.Version.Name
+	auto.Version.Protocol = status.Version.Protocol
+	auto.Players.Max = status.Players.Max
+	auto.Players.Online = status.Players.Online
+	auto.Status = &status
+
+	return auto
+}
+
+// autoFromStatusLegacy packages statusLegacy into a StatusAutoResponse.
+func autoFromStatusLegacy(statusLegacy StatusLegacyResponse) StatusAutoResponse {
+	auto := StatusAutoResponse{}
+	auto.Protocol = ProtocolStatusLegacy
+	auto.IP = statusLegacy.IP
+	auto.Port = statusLegacy.Port
+	auto.Latency = statusLegacy.Latency
+	auto.Description = statusLegacy.Description
+	auto.Version.Name = statusLegacy.Version.Name
+	auto.Version.Protocol = statusLegacy.Version.Protocol
+	auto.Players.Max = statusLegacy.Players.Max
+	auto.Players.Online = statusLegacy.Players.Online
+	auto.StatusLegacy = &statusLegacy
+
+	return auto
+}
+
+// autoFromStatusBeta packages statusBeta into a StatusAutoResponse.
+func autoFromStatusBeta(statusBeta StatusBetaResponse) StatusAutoResponse {
+	auto := StatusAutoResponse{}
+	auto.Protocol = ProtocolStatusBeta
+	auto.IP = statusBeta.IP
+	auto.Port = statusBeta.Port
+	auto.Latency = statusBeta.Latency
+	auto.Description = statusBeta.Description
+	auto.Players.Max = statusBeta.Players.Max
+	auto.Players.Online = statusBeta.Players.Online
+	auto.StatusBeta = &statusBeta
+
+	return auto
+}
@@ -0,0 +1,92 @@
+package mcstatusgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatusAutoDoesntFallBack(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		server string
+		port   uint16
+		opts   []Option
+		want   error
+	}{
+		{"invalid port", "127.0.0.1", 0, nil, ErrInvalidArgument},
+		{"invalid hostname", "bad\u200d.example", 25565, nil, ErrInvalidHostname},
+		{"cancelled context", "127.0.0.1", 25565, []Option{WithContext(cancelled)}, context.Canceled},
+	}
+
+	for _, test := range tests {
+		attempted := []StatusProtocol{}
+		hooks := Hooks{OnError: func(protocol StatusProtocol, err error) { attempted = append(attempted, protocol) }}
+
+		_, err := StatusAuto(test.server, test.port, testTimeout, testTimeout, append(test.opts, WithHooks(hooks))...)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: StatusAuto() error = %v, want %v", test.name, err, test.want)
+		}
+		if len(attempted) != 1 || attempted[0] != ProtocolStatus {
+			t.Errorf("%s: attempted %v, want only %v", test.name, attempted, ProtocolStatus)
+		}
+	}
+}
+
+func TestStatusAutoFallsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(con net.Conn)
+		want   StatusProtocol
+	}{
+		{
+			// Servers older than 1.7 answer every request with a legacy kick packet, which the modern protocol rejects.
+			name: "legacy",
+			handle: func(con net.Conn) {
+				con.Read(make([]byte, 512))
+				con.Write(legacyResponse("§1\x00127\x001.6.4\x00A Minecraft Server\x003\x0020"))
+			},
+			want: ProtocolStatusLegacy,
+		},
+		{
+			// Beta servers drop anything but the lone 0xFE byte of the beta request.
+			name: "beta",
+			handle: func(con net.Conn) {
+				request := make([]byte, 512)
+				bytesRead, err := con.Read(request)
+				if err != nil || bytesRead != 1 || request[0] != 0xFE {
+					return
+				}
+
+				// The legacy request also starts with 0xFE, so it is told apart by the bytes following it.
+				con.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+				if _, err := con.Read(request); err == nil {
+					return
+				}
+				con.Write(legacyResponse("A Minecraft Server§3§20"))
+			},
+			want: ProtocolStatusBeta,
+		},
+	}
+
+	for _, test := range tests {
+		port := startFakeServer(t, test.handle)
+
+		auto, err := StatusAuto("127.0.0.1", port, testTimeout, testTimeout)
+		if err != nil {
+			t.Errorf("%s: StatusAuto: %v", test.name, err)
+			continue
+		}
+		if auto.Protocol != test.want {
+			t.Errorf("%s: Protocol = %v, want %v", test.name, auto.Protocol, test.want)
+		}
+		if auto.Description != "A Minecraft Server" || auto.Players.Online != 3 || auto.Players.Max != 20 {
+			t.Errorf("%s: response = %q with %d/%d players, want %q with 3/20", test.name, auto.Description, auto.Players.Online, auto.Players.Max, "A Minecraft Server")
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package mcstatusgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Errors.
+var (
+	// ErrQueryPortNotFound is returned when none of the candidate ports respond to the query.
+	ErrQueryPortNotFound error = errors.New("query port not found: none of the candidate ports responded to the query")
+)
+
+// PortStatusResult contains the result of the status request to a single port.
+type PortStatusResult struct {
+	// Status contains the response if the request succeeded.
+	Status StatusResponse
+
+	// Err contains the error if the request failed.
+	Err error
+}
+
+// StatusPorts concurrently requests basic server information from every port of a Minecraft server, such as a host running several servers.
+//
+// The server's hostname is resolved once and every port is dialed at the resolved IP, while the hostname is still sent in each handshake.
+// If the hostname can't be resolved, every port's result contains the resolution error.
+//
+// The result of each port is returned in a map keyed by port.
+// The Stats given with WithStats receive the traffic of every port added together, and the hooks are never called concurrently.
+// https://wiki.vg/Server_List_Ping
+func StatusPorts(server string, ports []uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) map[uint16]PortStatusResult {
+	options := newOptions(opts)
+	results := make(map[uint16]PortStatusResult)
+
+	serverIP, err := resolveServer(server, initialConnectionTimeout, options)
+	if err != nil {
+		for _, port := range ports {
+			results[port] = PortStatusResult{Err: err}
+		}
+
+		return results
+	}
+
+	group := newRequestGroup(options)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, port := range ports {
+		wg.Add(1)
+
+		go func(port uint16) {
+			defer wg.Done()
+
+			requestOptions := group.request()
+			status, err := statusDial(serverIP, server, port, initialConnectionTimeout, ioTimeout, requestOptions)
+			group.add(requestOptions)
+
+			mutex.Lock()
+			results[port] = PortStatusResult{status, err}
+			mutex.Unlock()
+		}(port)
+	}
+	wg.Wait()
+	group.done()
+
+	return results
+}
+
+// DiscoverQueryPort concurrently sends a basic query to every candidate port of a Minecraft server and returns the first port that responds along with its response.
+//
+// The query port is set by the "query.port" property, which often differs from the game port.
+// The server's hostname is resolved once and every port is queried at the resolved IP.
+// Once a port responds, the queries still in flight are cancelled and waited for, so no hook or trace outlives the call.
+// If no port responds, an error matching ErrQueryPortNotFound is returned, which also wraps the error of the last port that failed.
+// The Stats given with WithStats receive the traffic of the query to the port found, and the hooks are never called concurrently.
+// https://wiki.vg/Query#Basic_stat
+func DiscoverQueryPort(server string, ports []uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (uint16, BasicQueryResponse, error) {
+	options := newOptions(opts)
+
+	serverIP, err := resolveServer(server, initialConnectionTimeout, options)
+	if err != nil {
+		return 0, BasicQueryResponse{}, err
+	}
+
+	type portResult struct {
+		port       uint16
+		basicQuery BasicQueryResponse
+		stats      *Stats
+		err        error
+	}
+
+	ctx, cancel := context.WithCancel(options.context())
+	defer cancel()
+	options.ctx = ctx
+
+	group := newRequestGroup(options)
+	var wg sync.WaitGroup
+
+	// The channel is buffered so the queries cancelled once a port has been found don't block.
+	results := make(chan portResult, len(ports))
+	for _, port := range ports {
+		wg.Add(1)
+
+		go func(port uint16) {
+			defer wg.Done()
+
+			requestOptions := group.request()
+			basicQuery, err := probeQueryPort(serverIP, port, initialConnectionTimeout, ioTimeout, requestOptions)
+			results <- portResult{port, basicQuery, requestOptions.stats, err}
+		}(port)
+	}
+	defer wg.Wait()
+
+	var lastErr error
+	for range ports {
+		result := <-results
+		if result.err == nil {
+			cancel()
+			group.record(result.stats)
+			return result.port, result.basicQuery, nil
+		}
+		lastErr = result.err
+	}
+
+	if lastErr == nil {
+		return 0, BasicQueryResponse{}, ErrQueryPortNotFound
+	}
+
+	return 0, BasicQueryResponse{}, queryPortNotFoundError{lastErr}
+}
+
+// probeQueryPort dials port and performs the basic query, closing the connection as soon as the context of options is cancelled.
+func probeQueryPort(serverIP string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (BasicQueryResponse, error) {
+	con, err := dial("udp", serverIP, port, initialConnectionTimeout, ProtocolBasicQuery, options)
+	if err != nil {
+		return BasicQueryResponse{}, err
+	}
+	defer con.Close()
+
+	stop := closeOnDone(options.context(), con)
+	defer stop()
+
+	return basicQueryConn(con, port, ioTimeout, options)
+}
+
+// queryPortNotFoundError marks the error of the last candidate port that failed as ErrQueryPortNotFound.
+type queryPortNotFoundError struct {
+	err error
+}
+
+func (e queryPortNotFoundError) Error() string {
+	return ErrQueryPortNotFound.Error() + ": " + e.err.Error()
+}
+
+// Unwrap returns the error of the last candidate port that failed.
+func (e queryPortNotFoundError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is ErrQueryPortNotFound.
+func (e queryPortNotFoundError) Is(target error) bool {
+	return target == ErrQueryPortNotFound
+}
+
+// resolveServer resolves the server's hostname into the first IP it points to, of the IP version forced by WithNetwork if any.
+//
+// The lookup stops when the context given by WithContext is cancelled or the overall timeout given by WithOverallTimeout runs out.
+func resolveServer(server string, timeout time.Duration, options options) (string, error) {
+	err := validateServer(server)
+	if err != nil {
+		return "", err
+	}
+
+	ipVersion, err := options.ipVersion()
+	if err != nil {
+		return "", err
+	}
+
+	asciiServer, err := toASCIIHostname(server)
+	if err != nil {
+		return "", err
+	}
+
+	options, cancelOverall := options.withOverallTimeout()
+	defer cancelOverall()
+
+	ctx, cancel := context.WithTimeout(options.context(), timeout)
+	defer cancel()
+
+	ipAddrs, err := options.netResolver().LookupIPAddr(ctx, asciiServer)
+	if err != nil {
+		return "", classifyNetworkError(err)
+	}
+
+	for _, ipAddr := range ipAddrs {
+		if matchesIPVersion(ipAddr.IP, ipVersion) {
+			return ipAddr.String(), nil
+		}
+	}
+
+	return "", classifyNetworkError(&net.DNSError{Err: "no address of IP version " + ipVersion + " found", Name: asciiServer, IsNotFound: true})
+}
+
+// matchesIPVersion reports whether ip is of ipVersion, which matches any IP if it is empty.
+func matchesIPVersion(ip net.IP, ipVersion string) bool {
+	switch ipVersion {
+	case "4":
+		return ip.To4() != nil
+	case "6":
+		return ip.To4() == nil
+	}
+
+	return true
+}
+
+// BatchTarget identifies a server requested by BatchStatus.
+type BatchTarget struct {
+	// Server contains the server's hostname or IP.
+	Server string
+
+	// Port contains the server's port.
+	Port uint16
+}
+
+// String returns the target as a "host:port" address.
+func (t BatchTarget) String() string {
+	return net.JoinHostPort(t.Server, strconv.Itoa(int(t.Port)))
+}
+
+// BatchResult contains the result of the status request to a single target.
+type BatchResult struct {
+	// Target contains the server that was requested.
+	Target BatchTarget
+
+	// Status contains the response if the request succeeded.
+	Status StatusResponse
+
+	// Err contains the error if the request failed.
+	Err error
+
+	// Time contains when the request completed.
+	Time time.Time
+}
+
+// BatchStatus concurrently requests basic server information from every target, with at most concurrency requests in flight.
+//
+// A concurrency less than 1 requests every target at once.
+// The Stats given with WithStats receive the traffic of every target added together, and the hooks are never called concurrently.
+// The results are returned in the same order as targets.
+// If the context given by WithContext is cancelled, the targets that weren't requested yet contain the context's error.
+// https://wiki.vg/Server_List_Ping
+func BatchStatus(targets []BatchTarget, concurrency int, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) []BatchResult {
+	options := newOptions(opts)
+	results := make([]BatchResult, len(targets))
+
+	batchStatus(targets, concurrency, initialConnectionTimeout, ioTimeout, options, func(i int, result BatchResult) {
+		results[i] = result
+	})
+
+	for i, result := range results {
+		if result.Time.IsZero() {
+			results[i] = BatchResult{Target: targets[i], Err: options.context().Err(), Time: time.Now()}
+		}
+	}
+
+	return results
+}
+
+// StatusStream concurrently requests basic server information from every target like BatchStatus, sending each result on the returned channel as soon as it completes.
+//
+// Every request is bounded by ctx. Once ctx is cancelled, no more targets are requested and the results of the requests still in flight are discarded.
+// The channel is closed when every request has finished or, promptly after ctx is cancelled, once the requests in flight have been interrupted.
+// https://wiki.vg/Server_List_Ping
+func StatusStream(ctx context.Context, targets []BatchTarget, concurrency int, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) <-chan BatchResult {
+	options := newOptions(opts)
+	options.ctx = ctx
+	stream := make(chan BatchResult)
+
+	go func() {
+		defer close(stream)
+
+		batchStatus(targets, concurrency, initialConnectionTimeout, ioTimeout, options, func(_ int, result BatchResult) {
+			select {
+			case stream <- result:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return stream
+}
+
+// WriteBatchStatusJSONLines concurrently requests basic server information from every target like BatchStatus, writing each result to w as a JSON object on its own line as soon as it completes.
+//
+// Results are written in the order they complete rather than held in memory, which suits piping long scans to disk.
+// Each line contains the target, the time the request completed, and either the status (with its description as a JSON object) or the error string.
+// If writing to w fails, the remaining requests still complete but their results are discarded, and the write error is returned.
+func WriteBatchStatusJSONLines(w io.Writer, targets []BatchTarget, concurrency int, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) error {
+	encoder := json.NewEncoder(w)
+	var writeErr error
+
+	batchStatus(targets, concurrency, initialConnectionTimeout, ioTimeout, newOptions(opts), func(_ int, result BatchResult) {
+		if writeErr != nil {
+			return
+		}
+
+		writeErr = encoder.Encode(newBatchResultLine(result))
+	})
+
+	return writeErr
+}
+
+// batchResultLine is the JSON Lines representation of a BatchResult.
+type batchResultLine struct {
+	Target      string          `json:"target"`
+	Time        time.Time       `json:"time"`
+	Status      *StatusResponse `json:"status,omitempty"`
+	Description json.RawMessage `json:"description,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// newBatchResultLine converts result into its JSON Lines representation.
+//
+// The description is included separately, as it is excluded from the JSON encoding of StatusResponse.
+func newBatchResultLine(result BatchResult) batchResultLine {
+	line := batchResultLine{}
+	line.Target = result.Target.String()
+	line.Time = result.Time
+
+	if result.Err != nil {
+		line.Error = result.Err.Error()
+		return line
+	}
+
+	line.Status = &result.Status
+	line.Description = json.RawMessage(result.Status.Description)
+
+	return line
+}
+
+// batchStatus requests every target with at most concurrency requests in flight, calling handle with the index of each target and its result as each request completes.
+//
+// handle is never called concurrently. No more targets are requested once the context of options is cancelled.
+// The Stats given with WithStats receive the traffic of every request added together, and the hooks are never called concurrently.
+func batchStatus(targets []BatchTarget, concurrency int, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options, handle func(int, BatchResult)) {
+	if concurrency < 1 || concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+
+	type indexedResult struct {
+		index  int
+		result BatchResult
+	}
+
+	group := newRequestGroup(options)
+	indexes := make(chan int)
+	results := make(chan indexedResult)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				target := targets[i]
+				requestOptions := group.request()
+				status, err := statusDial(target.Server, target.Server, target.Port, initialConnectionTimeout, ioTimeout, requestOptions)
+				group.add(requestOptions)
+				results <- indexedResult{i, BatchResult{target, status, err, time.Now()}}
+			}
+		}()
+	}
+
+	go func() {
+		ctx := options.context()
+
+	dispatch:
+		for i := range targets {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		close(indexes)
+
+		wg.Wait()
+		close(results)
+	}()
+
+	for indexed := range results {
+		handle(indexed.index, indexed.result)
+	}
+	group.done()
+}
+
+// requestGroup hands out the options of the requests made concurrently by a single call, such as one per port by StatusPorts.
+//
+// The trace writer and hooks are shared by every request, so their writes and calls are serialized.
+// Each request records its traffic into its own Stats, which are either added together by add and copied into the Stats given with WithStats by done, or copied on their own by record.
+type requestGroup struct {
+	options options
+	mutex   *sync.Mutex
+	stats   Stats
+}
+
+// newRequestGroup creates a requestGroup sharing options between its requests.
+func newRequestGroup(options options) *requestGroup {
+	mutex := &sync.Mutex{}
+	if options.traceWriter != nil {
+		options.traceWriter = lockedWriter{options.traceWriter, mutex}
+	}
+	options.hooks = options.hooks.locked(mutex)
+
+	return &requestGroup{options: options, mutex: mutex}
+}
+
+// request returns the options of a single request, which records its traffic into its own Stats if WithStats was given.
+func (g *requestGroup) request() options {
+	requestOptions := g.options
+	if requestOptions.stats != nil {
+		requestOptions.stats = &Stats{}
+	}
+
+	return requestOptions
+}
+
+// add adds the traffic of a finished request, whose options were returned by request, to the group.
+func (g *requestGroup) add(requestOptions options) {
+	if requestOptions.stats == nil {
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.stats.add(*requestOptions.stats)
+}
+
+// done copies the traffic of every added request into the Stats given with WithStats, if any.
+func (g *requestGroup) done() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.options.stats != nil {
+		*g.options.stats = g.stats
+	}
+}
+
+// record copies the traffic of a single finished request, recorded into the Stats of the options returned by request, into the Stats given with WithStats, if any.
+//
+// It is used instead of add and done when only one request is reported, such as the port found by DiscoverQueryPort or the latest request of a Client.
+func (g *requestGroup) record(stats *Stats) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.options.recordStats(stats)
+}
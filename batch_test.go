@@ -0,0 +1,244 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyOptions returns options recording stats, a trace, and hooks, which are shared by the requests of the multi-target helpers, along with the number of responses hooked.
+func concurrencyOptions(stats *Stats, trace *bytes.Buffer) ([]Option, *int) {
+	responses := 0
+	reads := 0
+	hooks := Hooks{
+		OnRead:     func(StatusProtocol, int, time.Duration) { reads++ },
+		OnResponse: func(StatusProtocol, int, time.Duration) { responses++ },
+	}
+
+	return []Option{WithStats(stats), WithTrace(trace), WithHooks(hooks)}, &responses
+}
+
+func TestStatusPortsSharedOptions(t *testing.T) {
+	ports := []uint16{
+		startFakeServer(t, serveStatus(testDocument, 0)),
+		startFakeServer(t, serveStatus(testDocument, 0)),
+		startFakeServer(t, serveStatus(testDocument, 0)),
+	}
+
+	stats := &Stats{}
+	trace := &bytes.Buffer{}
+	opts, responses := concurrencyOptions(stats, trace)
+
+	results := StatusPorts("127.0.0.1", ports, testTimeout, testTimeout, opts...)
+
+	total := Stats{}
+	for port, result := range results {
+		if result.Err != nil {
+			t.Fatalf("port %d: %v", port, result.Err)
+		}
+		total.BytesSent += result.Status.BytesSent
+		total.BytesReceived += result.Status.BytesReceived
+	}
+
+	if stats.BytesSent != total.BytesSent || stats.BytesReceived != total.BytesReceived {
+		t.Errorf("stats = %d/%d bytes, want the total of every port %d/%d", stats.BytesSent, stats.BytesReceived, total.BytesSent, total.BytesReceived)
+	}
+	if *responses != len(ports) {
+		t.Errorf("OnResponse fired %d times, want %d", *responses, len(ports))
+	}
+	if trace.Len() == 0 {
+		t.Error("nothing was traced")
+	}
+}
+
+func TestBatchStatusSharedOptions(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+	targets := []BatchTarget{{"127.0.0.1", port}, {"127.0.0.1", port}, {"127.0.0.1", port}, {"127.0.0.1", port}}
+
+	// totalBytes adds up the bytes sent by every successful result, failing the test on any error.
+	totalBytes := func(name string, results []BatchResult) int {
+		total := 0
+		for _, result := range results {
+			if result.Err != nil {
+				t.Fatalf("%s: %s: %v", name, result.Target, result.Err)
+			}
+			total += result.Status.BytesSent
+		}
+
+		return total
+	}
+
+	stats := &Stats{}
+	opts, responses := concurrencyOptions(stats, &bytes.Buffer{})
+	total := totalBytes("BatchStatus", BatchStatus(targets, 2, testTimeout, testTimeout, opts...))
+	if stats.BytesSent != total || *responses != len(targets) {
+		t.Errorf("BatchStatus: stats = %d bytes sent with %d responses hooked, want %d with %d", stats.BytesSent, *responses, total, len(targets))
+	}
+
+	stats = &Stats{}
+	opts, responses = concurrencyOptions(stats, &bytes.Buffer{})
+	results := []BatchResult{}
+	for result := range StatusStream(context.Background(), targets, 2, testTimeout, testTimeout, opts...) {
+		results = append(results, result)
+	}
+	total = totalBytes("StatusStream", results)
+	if stats.BytesSent != total || *responses != len(targets) {
+		t.Errorf("StatusStream: stats = %d bytes sent with %d responses hooked, want %d with %d", stats.BytesSent, *responses, total, len(targets))
+	}
+
+	stats = &Stats{}
+	opts, responses = concurrencyOptions(stats, &bytes.Buffer{})
+	lines := &bytes.Buffer{}
+	if err := WriteBatchStatusJSONLines(lines, targets, 2, testTimeout, testTimeout, opts...); err != nil {
+		t.Fatalf("WriteBatchStatusJSONLines: %v", err)
+	}
+	if stats.BytesSent == 0 || *responses != len(targets) || bytes.Count(lines.Bytes(), []byte("\n")) != len(targets) {
+		t.Errorf("WriteBatchStatusJSONLines: stats = %d bytes sent with %d responses hooked and %d lines, want %d responses and lines", stats.BytesSent, *responses, bytes.Count(lines.Bytes(), []byte("\n")), len(targets))
+	}
+}
+
+func TestDiscoverQueryPortSharedOptions(t *testing.T) {
+	ports := []uint16{}
+	for i := 0; i < 3; i++ {
+		server := startFakeQueryServer(t)
+		ports = append(ports, uint16(server.con.LocalAddr().(*net.UDPAddr).Port))
+	}
+
+	stats := &Stats{}
+	opts, _ := concurrencyOptions(stats, &bytes.Buffer{})
+
+	// Every query fires exactly one outcome hook, so the queries cancelled once a port is found must have fired theirs before the call returned.
+	var mutex sync.Mutex
+	outcomes := 0
+	countOutcome := func() {
+		mutex.Lock()
+		outcomes++
+		mutex.Unlock()
+	}
+	opts = append(opts, WithHooks(Hooks{
+		OnResponse: func(StatusProtocol, int, time.Duration) { countOutcome() },
+		OnError:    func(StatusProtocol, error) { countOutcome() },
+	}))
+
+	port, basicQuery, err := DiscoverQueryPort("127.0.0.1", ports, testTimeout, testTimeout, opts...)
+	if err != nil {
+		t.Fatalf("DiscoverQueryPort: %v", err)
+	}
+	mutex.Lock()
+	if outcomes != len(ports) {
+		t.Errorf("%d outcome hooks fired before DiscoverQueryPort returned, want %d", outcomes, len(ports))
+	}
+	mutex.Unlock()
+	if basicQuery.Port != port {
+		t.Errorf("response port = %d, want the port found %d", basicQuery.Port, port)
+	}
+	if stats.BytesSent == 0 || stats.BytesSent != basicQuery.BytesSent || stats.BytesReceived != basicQuery.BytesReceived {
+		t.Errorf("stats = %d/%d bytes, want the bytes of the query to the port found %d/%d", stats.BytesSent, stats.BytesReceived, basicQuery.BytesSent, basicQuery.BytesReceived)
+	}
+}
+
+func TestResolveServerContext(t *testing.T) {
+	// The resolver never answers, so only the context of the request can end the lookup before the timeout.
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"context", WithContext(func() context.Context {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			t.Cleanup(cancel)
+			return ctx
+		}())},
+		{"overall timeout", WithOverallTimeout(50 * time.Millisecond)},
+	}
+
+	for _, test := range tests {
+		startTime := time.Now()
+		_, err := resolveServer("mc.example.test", 10*time.Second, newOptions([]Option{WithResolver(resolver), test.opt}))
+		if err == nil {
+			t.Errorf("%s: resolveServer() succeeded without a DNS answer", test.name)
+		}
+		if elapsed := time.Since(startTime); elapsed > 5*time.Second {
+			t.Errorf("%s: resolveServer() took %v, want the lookup to stop with the context", test.name, elapsed)
+		}
+	}
+}
+
+func TestDiscoverQueryPortNotFound(t *testing.T) {
+	// The listeners never answer, like ports with query disabled.
+	ports := []uint16{}
+	for i := 0; i < 2; i++ {
+		con, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer con.Close()
+		ports = append(ports, uint16(con.LocalAddr().(*net.UDPAddr).Port))
+	}
+
+	_, _, err := DiscoverQueryPort("127.0.0.1", ports, queryTestTimeout, queryTestTimeout)
+	if !errors.Is(err, ErrQueryPortNotFound) || !errors.Is(err, ErrQueryUnavailable) {
+		t.Errorf("DiscoverQueryPort() = %v, want an error matching ErrQueryPortNotFound and ErrQueryUnavailable", err)
+	}
+}
+
+func TestDiscoverQueryPortCancelsProbes(t *testing.T) {
+	found := startFakeQueryServer(t)
+
+	// The silent listener never answers, so its query only ends early if it is cancelled.
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer silent.Close()
+
+	ports := []uint16{uint16(found.con.LocalAddr().(*net.UDPAddr).Port), uint16(silent.LocalAddr().(*net.UDPAddr).Port)}
+
+	startTime := time.Now()
+	port, _, err := DiscoverQueryPort("127.0.0.1", ports, testTimeout, 10*time.Second)
+	if err != nil {
+		t.Fatalf("DiscoverQueryPort: %v", err)
+	}
+	if port != ports[0] {
+		t.Errorf("port = %d, want %d", port, ports[0])
+	}
+	if elapsed := time.Since(startTime); elapsed > 5*time.Second {
+		t.Errorf("DiscoverQueryPort() took %v, want the unanswered query to be cancelled", elapsed)
+	}
+}
+
+func TestStatusStreamClosesOnCancel(t *testing.T) {
+	// The server accepts the connection but never answers, so the request only ends early if it is interrupted.
+	hang := make(chan struct{})
+	defer close(hang)
+	port := startFakeServer(t, func(net.Conn) { <-hang })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := StatusStream(ctx, []BatchTarget{{"127.0.0.1", port}}, 1, testTimeout, 10*time.Second)
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("StatusStream() channel wasn't closed promptly after ctx was cancelled")
+		}
+	}
+}
@@ -0,0 +1,515 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// legacyCodePrefix starts a legacy formatting code, which is followed by a single character selecting a color or style.
+	// https://wiki.vg/Chat#Colors
+	legacyCodePrefix string = "§"
+	// ansiReset resets the color and style of the terminal.
+	ansiReset string = "\x1b[0m"
+	// ansiDefaultColor resets the foreground color of the terminal.
+	ansiDefaultColor string = "\x1b[39m"
+)
+
+var (
+	// translations maps the translation keys commonly sent in server descriptions to their English text.
+	translations map[string]string = map[string]string{
+		"multiplayer.status.cannot_connect":      "Can't connect to server",
+		"multiplayer.status.cancelled":           "Cancelled",
+		"multiplayer.status.incompatible":        "Incompatible version!",
+		"multiplayer.status.no_connection":       "(no connection)",
+		"multiplayer.status.old":                 "Old",
+		"multiplayer.status.pinging":             "Pinging...",
+		"multiplayer.status.quitting":            "Quitting",
+		"multiplayer.status.request_handled":     "Status request has been handled",
+		"multiplayer.status.unknown":             "???",
+		"multiplayer.status.unrequested":         "Received unrequested status",
+		"multiplayer.disconnect.server_shutdown": "Server closed",
+		"multiplayer.disconnect.not_whitelisted": "You are not white-listed on this server!",
+		"disconnect.closed":                      "Connection closed",
+		"disconnect.timeout":                     "Timed out",
+		"chat.type.text":                         "<%s> %s",
+		"chat.type.announcement":                 "[%s] %s",
+	}
+	// namedColors maps each named chat color to its hex color.
+	// https://wiki.vg/Chat#Colors
+	namedColors map[string]string = map[string]string{
+		"black":        "#000000",
+		"dark_blue":    "#0000aa",
+		"dark_green":   "#00aa00",
+		"dark_aqua":    "#00aaaa",
+		"dark_red":     "#aa0000",
+		"dark_purple":  "#aa00aa",
+		"gold":         "#ffaa00",
+		"gray":         "#aaaaaa",
+		"dark_gray":    "#555555",
+		"blue":         "#5555ff",
+		"green":        "#55ff55",
+		"aqua":         "#55ffff",
+		"red":          "#ff5555",
+		"light_purple": "#ff55ff",
+		"yellow":       "#ffff55",
+		"white":        "#ffffff",
+	}
+	// legacyColors maps the character of each legacy color code to its named color.
+	// https://wiki.vg/Chat#Colors
+	legacyColors map[rune]string = map[rune]string{
+		'0': "black",
+		'1': "dark_blue",
+		'2': "dark_green",
+		'3': "dark_aqua",
+		'4': "dark_red",
+		'5': "dark_purple",
+		'6': "gold",
+		'7': "gray",
+		'8': "dark_gray",
+		'9': "blue",
+		'a': "green",
+		'b': "aqua",
+		'c': "red",
+		'd': "light_purple",
+		'e': "yellow",
+		'f': "white",
+	}
+	// legacyStyles maps the character of each legacy style code to its ANSI style code.
+	// Obfuscated text ("§k") has no ANSI equivalent, so it isn't included.
+	legacyStyles map[rune]int = map[rune]int{
+		'l': 1,
+		'm': 9,
+		'n': 4,
+		'o': 3,
+	}
+
+	// ansiColors contains the hex color of each named chat color along with the ANSI foreground color code closest to it.
+	// A slice is used so the nearest color is chosen deterministically when distances tie.
+	ansiColors []ansiColor = []ansiColor{
+		{"#000000", 30},
+		{"#0000aa", 34},
+		{"#00aa00", 32},
+		{"#00aaaa", 36},
+		{"#aa0000", 31},
+		{"#aa00aa", 35},
+		{"#ffaa00", 33},
+		{"#aaaaaa", 37},
+		{"#555555", 90},
+		{"#5555ff", 94},
+		{"#55ff55", 92},
+		{"#55ffff", 96},
+		{"#ff5555", 91},
+		{"#ff55ff", 95},
+		{"#ffff55", 93},
+		{"#ffffff", 97},
+	}
+)
+
+// ansiColor pairs a chat color with an ANSI foreground color code.
+type ansiColor struct {
+	hexColor string
+	code     int
+}
+
+// TextComponent contains a chat component, the format used by the status protocol for the server description.
+//
+// The style fields are nil when the component inherits the style from its parent.
+// https://wiki.vg/Chat
+type TextComponent struct {
+	// Text contains the text of the component.
+	Text string `json:"text"`
+
+	// Color contains the color of the text, either as a named color (such as "gold") or a "#rrggbb" hex color.
+	Color string `json:"color,omitempty"`
+
+	// Bold contains whether the text is bold.
+	Bold *bool `json:"bold,omitempty"`
+
+	// Italic contains whether the text is italic.
+	Italic *bool `json:"italic,omitempty"`
+
+	// Underlined contains whether the text is underlined.
+	Underlined *bool `json:"underlined,omitempty"`
+
+	// Strikethrough contains whether the text is struck through.
+	Strikethrough *bool `json:"strikethrough,omitempty"`
+
+	// Obfuscated contains whether the text is obfuscated.
+	Obfuscated *bool `json:"obfuscated,omitempty"`
+
+	// Translate contains the translation key of the component, which is displayed in place of Text by the client.
+	Translate string `json:"translate,omitempty"`
+
+	// With contains the arguments substituted into the translation.
+	With []TextComponent `json:"with,omitempty"`
+
+	// Extra contains the sibling components that follow the text and inherit its style.
+	Extra []TextComponent `json:"extra,omitempty"`
+}
+
+// UnmarshalJSON normalizes the string, object, and array forms of a chat component into a TextComponent.
+//
+// A string is converted into a component containing only the text, as are numbers and booleans, which are sent as translation arguments.
+// An array is converted into its first component with the rest of the components appended to its siblings.
+func (t *TextComponent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	switch data[0] {
+	case '"':
+		var text string
+		err := json.Unmarshal(data, &text)
+		if err != nil {
+			return err
+		}
+
+		*t = TextComponent{Text: text}
+	case '[':
+		var components []TextComponent
+		err := json.Unmarshal(data, &components)
+		if err != nil {
+			return err
+		}
+
+		if len(components) == 0 {
+			*t = TextComponent{}
+			return nil
+		}
+
+		root := components[0]
+		root.Extra = append(root.Extra, components[1:]...)
+		*t = root
+	case '{':
+		// objectComponent has no methods, so unmarshalling into it doesn't recurse into UnmarshalJSON.
+		type objectComponent TextComponent
+
+		var component objectComponent
+		err := json.Unmarshal(data, &component)
+		if err != nil {
+			return err
+		}
+
+		*t = TextComponent(component)
+	default:
+		*t = TextComponent{Text: string(data)}
+	}
+
+	return nil
+}
+
+// DescriptionComponent parses the description into its chat component tree.
+func (s StatusResponse) DescriptionComponent() (TextComponent, error) {
+	var component TextComponent
+
+	err := json.Unmarshal([]byte(s.Description), &component)
+	if err != nil {
+		return TextComponent{}, err
+	}
+
+	return component, nil
+}
+
+// DescriptionColors returns the distinct colors used by the description in the order they first appear, each as a lowercase "#rrggbb" hex color.
+//
+// Named colors and legacy color codes ("§0" to "§f") embedded in the text are resolved to their hex colors,
+// while the custom hex colors supported since 1.16 are kept as is.
+// Unknown colors are skipped, and an empty slice is returned if the description can't be parsed.
+func (s StatusResponse) DescriptionColors() []string {
+	colors := []string{}
+
+	component, err := s.DescriptionComponent()
+	if err != nil {
+		return colors
+	}
+
+	seen := make(map[string]bool)
+	component.walkColors(func(color string) {
+		if !seen[color] {
+			seen[color] = true
+			colors = append(colors, color)
+		}
+	})
+
+	return colors
+}
+
+// walkColors calls visit with the hex color of t, the colors of the legacy color codes in its text, and the colors of each of its siblings, depth first.
+func (t TextComponent) walkColors(visit func(color string)) {
+	color, ok := colorToHex(t.Color)
+	if ok {
+		visit(color)
+	}
+
+	scanLegacyCodes(t.Text, func(text string) {}, func(code rune) {
+		legacyColor, ok := legacyColors[code]
+		if ok {
+			visit(namedColors[legacyColor])
+		}
+	})
+
+	for _, with := range t.With {
+		with.walkColors(visit)
+	}
+	for _, extra := range t.Extra {
+		extra.walkColors(visit)
+	}
+}
+
+// colorToHex converts a named or "#rrggbb" chat color into a lowercase hex color.
+func colorToHex(color string) (string, bool) {
+	color = strings.ToLower(color)
+
+	hexColor, ok := namedColors[color]
+	if ok {
+		return hexColor, true
+	}
+
+	if len(color) != 7 || color[0] != '#' {
+		return "", false
+	}
+
+	_, err := hex.DecodeString(color[1:])
+	if err != nil {
+		return "", false
+	}
+
+	return color, true
+}
+
+// PlainText returns the text of t and its siblings without any formatting.
+//
+// Translated components are replaced by the English text of common translation keys, with their arguments substituted.
+// Unknown translation keys are returned as is, so the text isn't silently blank.
+// Legacy formatting codes ("§" followed by a character) embedded in the text are removed.
+func (t TextComponent) PlainText() string {
+	var builder strings.Builder
+	t.writePlainText(&builder)
+
+	return builder.String()
+}
+
+// writePlainText writes the text of t and its siblings to builder, depth first.
+func (t TextComponent) writePlainText(builder *strings.Builder) {
+	scanLegacyCodes(t.displayText(), func(text string) {
+		builder.WriteString(text)
+	}, func(code rune) {})
+
+	for _, extra := range t.Extra {
+		extra.writePlainText(builder)
+	}
+}
+
+// ANSIText returns the text of t and its siblings colored with ANSI escape codes for terminal output.
+//
+// Named colors are mapped to the matching ANSI color, and the custom hex colors supported since 1.16 are mapped to the nearest one.
+// Unknown colors are rendered in the default color of the terminal. Translated components are handled as in PlainText.
+// Legacy formatting codes embedded in the text are mapped too, except for obfuscated text ("§k"), which is rendered as is.
+// As in the client, "§r" resets the text to the color of its component.
+func (t TextComponent) ANSIText() string {
+	var builder strings.Builder
+	t.writeANSIText(&builder, "")
+	builder.WriteString(ansiReset)
+
+	return builder.String()
+}
+
+// writeANSIText writes the colored text of t and its siblings to builder, depth first, with siblings inheriting the color of t.
+func (t TextComponent) writeANSIText(builder *strings.Builder, inheritedColor string) {
+	color := inheritedColor
+	if t.Color != "" {
+		color = t.Color
+	}
+
+	builder.WriteString(ansiColorCode(color))
+
+	styled := false
+	scanLegacyCodes(t.displayText(), func(text string) {
+		builder.WriteString(text)
+	}, func(code rune) {
+		legacyColor, isColor := legacyColors[code]
+		style, isStyle := legacyStyles[code]
+
+		switch {
+		case isColor:
+			// Like in the client, a color code also resets the styles.
+			if styled {
+				builder.WriteString(ansiReset)
+				styled = false
+			}
+			builder.WriteString(ansiColorCode(legacyColor))
+		case isStyle:
+			builder.WriteString("\x1b[" + strconv.Itoa(style) + "m")
+			styled = true
+		case code == 'r':
+			builder.WriteString(ansiReset)
+			builder.WriteString(ansiColorCode(color))
+			styled = false
+		}
+	})
+
+	// Legacy styles only apply to the text of t, while siblings set their own color.
+	if styled {
+		builder.WriteString(ansiReset)
+	}
+
+	for _, extra := range t.Extra {
+		extra.writeANSIText(builder, color)
+	}
+}
+
+// ansiColorCode returns the ANSI escape code of the color nearest to the chat color, or the default color if it is unknown.
+func ansiColorCode(color string) string {
+	hexColor, ok := colorToHex(color)
+	if !ok {
+		return ansiDefaultColor
+	}
+
+	r, g, b := hexToRGB(hexColor)
+	nearest := ansiColors[0]
+	nearestDistance := -1
+	for _, candidate := range ansiColors {
+		candidateR, candidateG, candidateB := hexToRGB(candidate.hexColor)
+		distance := (r-candidateR)*(r-candidateR) + (g-candidateG)*(g-candidateG) + (b-candidateB)*(b-candidateB)
+		if nearestDistance == -1 || distance < nearestDistance {
+			nearest = candidate
+			nearestDistance = distance
+		}
+	}
+
+	return "\x1b[" + strconv.Itoa(nearest.code) + "m"
+}
+
+// hexToRGB returns the red, green, and blue channels of a hex color already validated by colorToHex.
+func hexToRGB(hexColor string) (int, int, int) {
+	channels, _ := hex.DecodeString(hexColor[1:])
+
+	return int(channels[0]), int(channels[1]), int(channels[2])
+}
+
+// displayText returns the text displayed for t itself, which is its translation if it is a translated component.
+func (t TextComponent) displayText() string {
+	if t.Translate != "" {
+		return t.translation()
+	}
+
+	return t.Text
+}
+
+// scanLegacyCodes splits text at its legacy formatting codes, calling visitText with each run of text and visitCode with the lowercase character of each code, in order.
+//
+// Like the client, a "§" at the end of text is dropped.
+func scanLegacyCodes(text string, visitText func(text string), visitCode func(code rune)) {
+	for {
+		i := strings.Index(text, legacyCodePrefix)
+		if i == -1 {
+			break
+		}
+		if i > 0 {
+			visitText(text[:i])
+		}
+
+		code, size := utf8.DecodeRuneInString(text[i+len(legacyCodePrefix):])
+		if size == 0 {
+			return
+		}
+		visitCode(unicode.ToLower(code))
+		text = text[i+len(legacyCodePrefix)+size:]
+	}
+
+	if text != "" {
+		visitText(text)
+	}
+}
+
+// stripLegacyCodes returns text without its legacy formatting codes, as displayed by the client.
+func stripLegacyCodes(text string) string {
+	var builder strings.Builder
+	scanLegacyCodes(text, func(text string) {
+		builder.WriteString(text)
+	}, func(code rune) {})
+
+	return builder.String()
+}
+
+// translation returns the English text of the translation key of t with its arguments substituted, or the key itself if it is unknown.
+// https://wiki.vg/Chat#Translation_component
+func (t TextComponent) translation() string {
+	format, ok := translations[t.Translate]
+	if !ok {
+		return t.Translate
+	}
+
+	args := make([]string, len(t.With))
+	for i, with := range t.With {
+		args[i] = with.PlainText()
+	}
+
+	return substituteTranslationArgs(format, args)
+}
+
+// substituteTranslationArgs replaces the "%s" and positional "%1$s" placeholders in format with args.
+func substituteTranslationArgs(format string, args []string) string {
+	var builder strings.Builder
+	nextArg := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			builder.WriteByte(format[i])
+			continue
+		}
+
+		switch {
+		case format[i+1] == '%':
+			builder.WriteByte('%')
+			i++
+		case format[i+1] == 's':
+			if nextArg < len(args) {
+				builder.WriteString(args[nextArg])
+			}
+			nextArg++
+			i++
+		case i+3 < len(format) && format[i+1] >= '1' && format[i+1] <= '9' && format[i+2] == '$' && format[i+3] == 's':
+			argIndex := int(format[i+1] - '1')
+			if argIndex < len(args) {
+				builder.WriteString(args[argIndex])
+			}
+			i += 3
+		default:
+			builder.WriteByte(format[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// PlainDescription returns the description as plain text without any formatting.
+//
+// See TextComponent.PlainText for how translated components are handled. An empty string is returned if the description can't be parsed.
+func (s StatusResponse) PlainDescription() string {
+	component, err := s.DescriptionComponent()
+	if err != nil {
+		return ""
+	}
+
+	return component.PlainText()
+}
+
+// ANSIDescription returns the description colored with ANSI escape codes for terminal output.
+//
+// See TextComponent.ANSIText for how colors are mapped. An empty string is returned if the description can't be parsed.
+func (s StatusResponse) ANSIDescription() string {
+	component, err := s.DescriptionComponent()
+	if err != nil {
+		return ""
+	}
+
+	return component.ANSIText()
+}
@@ -0,0 +1,101 @@
+package mcstatusgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestANSITextHexColors(t *testing.T) {
+	tests := []struct {
+		color string
+		want  string
+	}{
+		// Named colors map to their own ANSI color.
+		{"gold", "\x1b[33m"},
+		{"dark_gray", "\x1b[90m"},
+		// Exact hex colors of named colors map to the same ANSI color.
+		{"#FFAA00", "\x1b[33m"},
+		// Custom hex colors map to the nearest ANSI color.
+		{"#aabbcc", "\x1b[37m"},
+		{"#ff0001", "\x1b[31m"},
+		{"#10ff20", "\x1b[92m"},
+		{"#000010", "\x1b[30m"},
+		// Unknown colors fall back to the default color.
+		{"#zzzzzz", "\x1b[39m"},
+		{"#abc", "\x1b[39m"},
+		{"", "\x1b[39m"},
+	}
+
+	for _, test := range tests {
+		got := TextComponent{Text: "x", Color: test.color}.ANSIText()
+		if want := test.want + "x" + ansiReset; got != want {
+			t.Errorf("ANSIText() with color %q = %q, want %q", test.color, got, want)
+		}
+	}
+}
+
+func TestANSITextInheritsColor(t *testing.T) {
+	component := TextComponent{Text: "A", Color: "#aabbcc", Extra: []TextComponent{{Text: "B", Color: "#ff0001"}, {Text: "C"}}}
+
+	want := "\x1b[37mA\x1b[31mB\x1b[37mC" + ansiReset
+	if got := component.ANSIText(); got != want {
+		t.Errorf("ANSIText() = %q, want %q", got, want)
+	}
+
+	// The exact hex colors are kept by DescriptionColors.
+	status := StatusResponse{Description: `{"text":"A","color":"#aabbcc","extra":[{"text":"B","color":"#FF0001"}]}`}
+	colors := status.DescriptionColors()
+	if len(colors) != 2 || colors[0] != "#aabbcc" || colors[1] != "#ff0001" {
+		t.Errorf("DescriptionColors() = %v, want [#aabbcc #ff0001]", colors)
+	}
+}
+
+func TestPlainTextStripsLegacyCodes(t *testing.T) {
+	tests := []struct {
+		component TextComponent
+		want      string
+	}{
+		{TextComponent{Text: "§6Gold §lServer§r!"}, "Gold Server!"},
+		{TextComponent{Text: "§AUppercase"}, "Uppercase"},
+		{TextComponent{Text: "Trailing§"}, "Trailing"},
+		{TextComponent{Text: "§§"}, ""},
+		{TextComponent{Text: "A", Extra: []TextComponent{{Text: "§cB"}}}, "AB"},
+		{TextComponent{Translate: "chat.type.text", With: []TextComponent{{Text: "§eSteve"}, {Text: "hi"}}}, "<Steve> hi"},
+	}
+
+	for _, test := range tests {
+		if got := test.component.PlainText(); got != test.want {
+			t.Errorf("PlainText() of %+v = %q, want %q", test.component, got, test.want)
+		}
+	}
+}
+
+func TestDescriptionColorsLegacyCodes(t *testing.T) {
+	status := StatusResponse{Description: `{"text":"§6Gold §lbold §Cred","color":"aqua","extra":[{"text":"§r§6again §kobfuscated"}]}`}
+
+	colors := status.DescriptionColors()
+	want := []string{"#55ffff", "#ffaa00", "#ff5555"}
+	if strings.Join(colors, " ") != strings.Join(want, " ") {
+		t.Errorf("DescriptionColors() = %v, want %v", colors, want)
+	}
+}
+
+func TestANSITextLegacyCodes(t *testing.T) {
+	tests := []struct {
+		component TextComponent
+		want      string
+	}{
+		{TextComponent{Text: "§6Gold"}, "\x1b[39m\x1b[33mGold"},
+		{TextComponent{Text: "§lBold§cRed", Color: "white"}, "\x1b[97m\x1b[1mBold\x1b[0m\x1b[91mRed"},
+		{TextComponent{Text: "§4A§rB", Color: "gold"}, "\x1b[33m\x1b[31mA\x1b[0m\x1b[33mB"},
+		{TextComponent{Text: "§kSecret"}, "\x1b[39mSecret"},
+		// Styles are reset before the siblings.
+		{TextComponent{Text: "§nA", Extra: []TextComponent{{Text: "B"}}}, "\x1b[39m\x1b[4mA\x1b[0m\x1b[39mB"},
+	}
+
+	for _, test := range tests {
+		if got, want := test.component.ANSIText(), test.want+ansiReset; got != want {
+			t.Errorf("ANSIText() of %+v = %q, want %q", test.component, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Client holds a Minecraft server and the settings used to request information from it, which is useful for repeated polling.
+//
+// The server's hostname is resolved on the first request and the resolved IP is reused by later requests until a dial fails.
+// Each request still uses a new connection, as the protocols are one-shot.
+// A Client is safe for concurrent use. Concurrent requests share the trace writer and hooks without writing or calling them concurrently,
+// and the Stats given with WithStats receive the traffic of the latest request to finish.
+type Client struct {
+	server                   string
+	port                     uint16
+	initialConnectionTimeout time.Duration
+	ioTimeout                time.Duration
+	group                    *requestGroup
+
+	mutex      sync.Mutex
+	resolvedIP string
+}
+
+// NewClient creates a Client for the server using the given timeouts and options for every request.
+func NewClient(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) *Client {
+	return &Client{
+		server:                   server,
+		port:                     port,
+		initialConnectionTimeout: initialConnectionTimeout,
+		ioTimeout:                ioTimeout,
+		group:                    newRequestGroup(newOptions(opts)),
+	}
+}
+
+// Status requests basic server information from the server.
+// https://wiki.vg/Server_List_Ping
+func (c *Client) Status() (StatusResponse, error) {
+	serverIP, err := c.resolve()
+	if err != nil {
+		return StatusResponse{}, err
+	}
+
+	requestOptions := c.group.request()
+	status, err := statusDial(serverIP, c.server, c.port, c.initialConnectionTimeout, c.ioTimeout, requestOptions)
+	c.group.record(requestOptions.stats)
+	c.checkDialError(err)
+
+	return status, err
+}
+
+// Ping retrieves the server latency.
+// https://wiki.vg/Server_List_Ping#Ping
+func (c *Client) Ping() (time.Duration, error) {
+	status, err := c.Status()
+	if err != nil {
+		return -1, err
+	}
+	if status.PingErr != nil {
+		return -1, status.PingErr
+	}
+
+	return status.Latency, nil
+}
+
+// StatusLegacy requests basic server information from the server using the older legacy implementation of Status.
+// https://wiki.vg/Server_List_Ping#1.6
+func (c *Client) StatusLegacy() (StatusLegacyResponse, error) {
+	serverIP, err := c.resolve()
+	if err != nil {
+		return StatusLegacyResponse{}, err
+	}
+
+	requestOptions := c.group.request()
+	statusLegacy, err := statusLegacyDial(serverIP, c.server, c.port, c.initialConnectionTimeout, c.ioTimeout, requestOptions)
+	c.group.record(requestOptions.stats)
+	c.checkDialError(err)
+
+	return statusLegacy, err
+}
+
+// StatusBeta requests basic server information from the server using the beta (oldest version) implementation of Status.
+// https://wiki.vg/Server_List_Ping#Beta_1.8_to_1.3
+func (c *Client) StatusBeta() (StatusBetaResponse, error) {
+	serverIP, err := c.resolve()
+	if err != nil {
+		return StatusBetaResponse{}, err
+	}
+
+	requestOptions := c.group.request()
+	statusBeta, err := statusBetaDial(serverIP, c.port, c.initialConnectionTimeout, c.ioTimeout, requestOptions)
+	c.group.record(requestOptions.stats)
+	c.checkDialError(err)
+
+	return statusBeta, err
+}
+
+// BasicQuery requests basic server information from the server using the query protocol.
+// https://wiki.vg/Query#Basic_stat
+func (c *Client) BasicQuery() (BasicQueryResponse, error) {
+	serverIP, err := c.resolve()
+	if err != nil {
+		return BasicQueryResponse{}, err
+	}
+
+	requestOptions := c.group.request()
+	basicQuery, err := basicQueryDial(serverIP, c.port, c.initialConnectionTimeout, c.ioTimeout, requestOptions)
+	c.group.record(requestOptions.stats)
+	c.checkDialError(err)
+
+	return basicQuery, err
+}
+
+// FullQuery requests detailed server information from the server using the query protocol.
+// https://wiki.vg/Query#Full_stat
+func (c *Client) FullQuery() (FullQueryResponse, error) {
+	serverIP, err := c.resolve()
+	if err != nil {
+		return FullQueryResponse{}, err
+	}
+
+	requestOptions := c.group.request()
+	fullQuery, err := fullQueryDial(serverIP, c.port, c.initialConnectionTimeout, c.ioTimeout, requestOptions)
+	c.group.record(requestOptions.stats)
+	c.checkDialError(err)
+
+	return fullQuery, err
+}
+
+// ResetResolution discards the resolved IP so the next request resolves the server's hostname again.
+func (c *Client) ResetResolution() {
+	c.mutex.Lock()
+	c.resolvedIP = ""
+	c.mutex.Unlock()
+}
+
+// resolve returns the resolved IP of the server, resolving the server's hostname if it hasn't been resolved yet.
+func (c *Client) resolve() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.resolvedIP != "" {
+		return c.resolvedIP, nil
+	}
+
+	serverIP, err := resolveServer(c.server, c.initialConnectionTimeout, c.group.request())
+	if err != nil {
+		return "", err
+	}
+	c.resolvedIP = serverIP
+
+	return serverIP, nil
+}
+
+// checkDialError discards the resolved IP if err was caused by dialing it, as the server may have moved.
+func (c *Client) checkDialError(err error) {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		c.ResetResolution()
+	}
+}
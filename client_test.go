@@ -0,0 +1,46 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestClientConcurrentSharedOptions(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	stats := &Stats{}
+	trace := &bytes.Buffer{}
+	opts, responses := concurrencyOptions(stats, trace)
+	client := NewClient("127.0.0.1", port, testTimeout, testTimeout, opts...)
+
+	// Run with -race: the requests share the Stats, trace writer, and hooks of the Client.
+	const requests = 8
+	statuses := make([]StatusResponse, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			status, err := client.Status()
+			if err != nil {
+				t.Errorf("request %d: %v", i, err)
+			}
+			statuses[i] = status
+		}(i)
+	}
+	wg.Wait()
+
+	// Every request exchanges the same packets, so the latest one to finish recorded the same traffic as any other.
+	if stats.BytesSent != statuses[0].BytesSent || stats.BytesReceived != statuses[0].BytesReceived {
+		t.Errorf("stats = %d/%d bytes, want the traffic of a single request %d/%d", stats.BytesSent, stats.BytesReceived, statuses[0].BytesSent, statuses[0].BytesReceived)
+	}
+	if *responses != requests {
+		t.Errorf("OnResponse fired %d times, want %d", *responses, requests)
+	}
+	if trace.Len() == 0 {
+		t.Error("nothing was traced")
+	}
+}
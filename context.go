@@ -0,0 +1,63 @@
+package mcstatusgo
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// contextConn clamps the deadlines set on the net.Conn it wraps to the deadline of ctx, so io operations don't outlive the caller's context.
+type contextConn struct {
+	net.Conn
+	ctx context.Context
+}
+
+// SetDeadline sets the read and write deadlines of the wrapped net.Conn to the sooner of t and the deadline of ctx.
+func (c contextConn) SetDeadline(t time.Time) error {
+	return c.Conn.SetDeadline(clampDeadline(c.ctx, t))
+}
+
+// SetReadDeadline sets the read deadline of the wrapped net.Conn to the sooner of t and the deadline of ctx.
+func (c contextConn) SetReadDeadline(t time.Time) error {
+	return c.Conn.SetReadDeadline(clampDeadline(c.ctx, t))
+}
+
+// SetWriteDeadline sets the write deadline of the wrapped net.Conn to the sooner of t and the deadline of ctx.
+func (c contextConn) SetWriteDeadline(t time.Time) error {
+	return c.Conn.SetWriteDeadline(clampDeadline(c.ctx, t))
+}
+
+// clampDeadline returns the sooner of deadline and the deadline of ctx.
+//
+// A zero deadline, which disables the deadline, is also clamped if ctx has a deadline.
+func clampDeadline(ctx context.Context, deadline time.Time) time.Time {
+	ctxDeadline, ok := ctx.Deadline()
+	if !ok {
+		return deadline
+	}
+
+	if deadline.IsZero() || ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+
+	return deadline
+}
+
+// closeOnDone closes con once ctx is done, interrupting its pending io operations, until the returned stop function is called.
+func closeOnDone(ctx context.Context, con net.Conn) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			con.Close()
+		case <-stop:
+		}
+	}()
+
+	return func() { close(stop) }
+}
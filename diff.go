@@ -0,0 +1,172 @@
+package mcstatusgo
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DiffOption configures how two status responses are compared by Diff and Equal.
+type DiffOption func(*diffOptions)
+
+// diffOptions contains the optional settings used when comparing status responses.
+type diffOptions struct {
+	// onlineThreshold is the smallest change in the online player count that is reported.
+	onlineThreshold int
+}
+
+// newDiffOptions applies opts over the default diff options.
+func newDiffOptions(opts []DiffOption) diffOptions {
+	o := diffOptions{
+		onlineThreshold: 1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// DiffOnlineThreshold only reports a change in the online player count if it changed by at least threshold players.
+//
+// By default any change is reported. A threshold less than 1 ignores the online player count entirely.
+func DiffOnlineThreshold(threshold int) DiffOption {
+	return func(o *diffOptions) {
+		o.onlineThreshold = threshold
+	}
+}
+
+// Equal reports whether s and other contain the same server information.
+//
+// The same fields as Diff are compared, see Diff for the fields that are ignored.
+func (s StatusResponse) Equal(other StatusResponse, opts ...DiffOption) bool {
+	return len(s.Diff(other, opts...)) == 0
+}
+
+// Diff returns a human-readable description of each change from s to other.
+//
+// Only the server information is compared, so RemoteAddr, BytesSent, BytesReceived, Latency, and PingErr, which describe the request, are ignored.
+// The player sample is compared as a set of players, as servers usually send a random sample.
+// Players.SampleTruncated depends on the player sample limit of the request and Players.SampleBanners is derived from the sample, so both are ignored too.
+// An empty slice is returned if nothing changed.
+func (s StatusResponse) Diff(other StatusResponse, opts ...DiffOption) []string {
+	options := newDiffOptions(opts)
+	changes := []string{}
+
+	if s.IP != other.IP {
+		changes = append(changes, fmt.Sprintf("IP changed from %q to %q", s.IP, other.IP))
+	}
+	if s.Port != other.Port {
+		changes = append(changes, fmt.Sprintf("port changed from %d to %d", s.Port, other.Port))
+	}
+	if s.Description != other.Description {
+		changes = append(changes, "description changed")
+	}
+	if s.Favicon != other.Favicon {
+		changes = append(changes, "favicon changed")
+	}
+	if s.Version.Name != other.Version.Name {
+		changes = append(changes, fmt.Sprintf("version name changed from %q to %q", s.Version.Name, other.Version.Name))
+	}
+	if s.Version.Protocol != other.Version.Protocol {
+		changes = append(changes, fmt.Sprintf("version protocol changed from %d to %d", s.Version.Protocol, other.Version.Protocol))
+	}
+	if s.Players.Max != other.Players.Max {
+		changes = append(changes, fmt.Sprintf("max players changed from %d to %d", s.Players.Max, other.Players.Max))
+	}
+	if onlineChanged(s.Players.Online, other.Players.Online, options.onlineThreshold) {
+		changes = append(changes, fmt.Sprintf("online players changed from %d to %d", s.Players.Online, other.Players.Online))
+	}
+	if !equalStrings(sampleKeys(s.Players.Sample), sampleKeys(other.Players.Sample)) {
+		changes = append(changes, "player sample changed")
+	}
+	if s.ModInfo.Type != other.ModInfo.Type {
+		changes = append(changes, fmt.Sprintf("mod type changed from %q to %q", s.ModInfo.Type, other.ModInfo.Type))
+	}
+	if !equalMods(s.ModInfo.ModList, other.ModInfo.ModList) {
+		changes = append(changes, "mod list changed")
+	}
+	if s.ModInfo.NetworkVersion != other.ModInfo.NetworkVersion {
+		changes = append(changes, fmt.Sprintf("FML network version changed from %q to %q", s.ModInfo.NetworkVersion, other.ModInfo.NetworkVersion))
+	}
+	if !equalBoolPointers(s.EnforcesSecureChat, other.EnforcesSecureChat) {
+		changes = append(changes, fmt.Sprintf("enforces secure chat changed from %s to %s", formatBoolPointer(s.EnforcesSecureChat), formatBoolPointer(other.EnforcesSecureChat)))
+	}
+	if !equalBoolPointers(s.PreviewsChat, other.PreviewsChat) {
+		changes = append(changes, fmt.Sprintf("previews chat changed from %s to %s", formatBoolPointer(s.PreviewsChat), formatBoolPointer(other.PreviewsChat)))
+	}
+
+	return changes
+}
+
+// onlineChanged reports whether the online player count changed by at least threshold players.
+func onlineChanged(online int, otherOnline int, threshold int) bool {
+	if threshold < 1 {
+		return false
+	}
+
+	change := otherOnline - online
+	if change < 0 {
+		change = -change
+	}
+
+	return change >= threshold
+}
+
+// sampleKeys converts each player in sample into a string and sorts them so samples can be compared regardless of order.
+func sampleKeys(sample []map[string]string) []string {
+	keys := make([]string, 0, len(sample))
+	for _, player := range sample {
+		keys = append(keys, player["name"]+"\x00"+player["id"])
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// equalStrings reports whether a and b contain the same strings in the same order.
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalMods reports whether a and b contain the same mods in the same order.
+func equalMods(a []Mod, b []Mod) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalBoolPointers reports whether a and b are both nil or point to the same value.
+func equalBoolPointers(a *bool, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// formatBoolPointer formats b for a change description, using "unset" for nil.
+func formatBoolPointer(b *bool) string {
+	if b == nil {
+		return "unset"
+	}
+
+	return fmt.Sprint(*b)
+}
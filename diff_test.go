@@ -0,0 +1,32 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDiffIgnoresRequestFields(t *testing.T) {
+	status := parseTestDocument(t, `{"version":{"name":"1.20.4","protocol":765},"players":{"max":20,"online":2,"sample":[{"name":"a","id":"1"},{"name":"b","id":"2"}]},"description":""}`)
+
+	other := status
+	other.RemoteAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 25565}
+	other.BytesSent = 10
+	other.BytesReceived = 100
+	other.Latency = time.Second
+	other.PingErr = errors.New("no pong")
+	other.Players.SampleTruncated = true
+	other.Players.SampleBanners = []map[string]string{{"name": "banner", "id": "00000000-0000-0000-0000-000000000000"}}
+	other.Players.Sample = []map[string]string{status.Players.Sample[1], status.Players.Sample[0]}
+
+	if changes := status.Diff(other); len(changes) != 0 {
+		t.Errorf("Diff() = %v, want no changes", changes)
+	}
+
+	other.Players.Online = 3
+	other.Version.Name = "1.21"
+	if changes := status.Diff(other); len(changes) != 2 {
+		t.Errorf("Diff() = %v, want the online players and version name changes", changes)
+	}
+}
@@ -0,0 +1,128 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+const (
+	// wsaConnRefused is the Windows socket error code for a refused connection.
+	wsaConnRefused syscall.Errno = 10061
+)
+
+// Errors.
+var (
+	// ErrInvalidArgument is returned, wrapped with a description of the argument, when a request is made with a server or port that can't be dialed.
+	ErrInvalidArgument error = errors.New("invalid argument")
+	// ErrConnectionRefused is the Kind of a NetworkError caused by the server refusing the connection, usually because it is down.
+	ErrConnectionRefused error = errors.New("connection refused")
+	// ErrTimeout is the Kind of a NetworkError caused by a connection or io operation timing out, usually because the server is unreachable.
+	ErrTimeout error = errors.New("timeout")
+	// ErrDNS is the Kind of a NetworkError caused by a failure to resolve the server's hostname.
+	ErrDNS error = errors.New("DNS failure")
+)
+
+// NetworkError wraps a network error returned by a request with the Kind of failure, so monitoring can branch on it without matching error text.
+//
+// errors.Is matches both Kind and the wrapped error, and errors.As can still retrieve the original error, such as a *net.OpError.
+type NetworkError struct {
+	// ErrConnectionRefused, ErrTimeout, or ErrDNS.
+	Kind error
+	// The original error.
+	Err error
+}
+
+func (e NetworkError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original error.
+func (e NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the Kind of e.
+func (e NetworkError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// classifyNetworkError wraps err in a NetworkError if it was caused by a refused connection, a timeout, or a DNS failure, and otherwise returns err unchanged.
+func classifyNetworkError(err error) error {
+	var networkErr NetworkError
+	if err == nil || errors.As(err, &networkErr) {
+		return err
+	}
+
+	// DNS errors are checked first, as a DNS lookup timing out is a DNS failure.
+	switch {
+	case IsDNSError(err):
+		return NetworkError{ErrDNS, err}
+	case IsConnectionRefused(err):
+		return NetworkError{ErrConnectionRefused, err}
+	case IsTimeout(err):
+		return NetworkError{ErrTimeout, err}
+	}
+
+	return err
+}
+
+// IsTimeout reports whether err was caused by a connection or io operation timing out.
+func IsTimeout(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionRefused reports whether err was caused by the server refusing the connection.
+func IsConnectionRefused(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var errno syscall.Errno
+	return errors.As(err, &errno) && errno == wsaConnRefused
+}
+
+// IsDNSError reports whether err was caused by a failure to resolve the server's hostname.
+func IsDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// validateServerAddr checks that server and port can be dialed before any network activity.
+func validateServerAddr(server string, port uint16) error {
+	err := validateServer(server)
+	if err != nil {
+		return err
+	}
+
+	return validatePort(port)
+}
+
+// validateServer checks that server can identify a server before any network activity.
+func validateServer(server string) error {
+	if strings.TrimSpace(server) == "" {
+		return fmt.Errorf("%w: server is empty", ErrInvalidArgument)
+	}
+	if strings.ContainsAny(server, " \t\r\n") {
+		return fmt.Errorf("%w: server %q contains whitespace", ErrInvalidArgument, server)
+	}
+
+	return nil
+}
+
+// validatePort checks that port can be dialed before any network activity.
+func validatePort(port uint16) error {
+	if port == 0 {
+		return fmt.Errorf("%w: port is 0", ErrInvalidArgument)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestNetworkErrorPredicates(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	dnsErr := &net.DNSError{Err: "no such host", Name: "mc.example.test", IsNotFound: true}
+	dnsTimeout := &net.DNSError{Err: "i/o timeout", Name: "mc.example.test", IsTimeout: true}
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantRefused bool
+		wantDNS     bool
+		wantTimeout bool
+		wantKind    error
+	}{
+		{"raw ECONNREFUSED", syscall.ECONNREFUSED, true, false, false, ErrConnectionRefused},
+		{"dial refused", refused, true, false, false, ErrConnectionRefused},
+		{"refused wrapped with %w", fmt.Errorf("status: %w", refused), true, false, false, ErrConnectionRefused},
+		{"DNS error", dnsErr, false, true, false, ErrDNS},
+		{"DNS error wrapped with %w", fmt.Errorf("resolve: %w", dnsErr), false, true, false, ErrDNS},
+		// A DNS lookup timing out is a timeout, but is classified as a DNS failure.
+		{"DNS timeout", dnsTimeout, false, true, true, ErrDNS},
+		{"io timeout", timeout, false, false, true, ErrTimeout},
+		{"NetworkError", NetworkError{ErrConnectionRefused, refused}, true, false, false, ErrConnectionRefused},
+		{"unrelated error", errors.New("unexpected response"), false, false, false, nil},
+	}
+
+	for _, test := range tests {
+		if got := IsConnectionRefused(test.err); got != test.wantRefused {
+			t.Errorf("%s: IsConnectionRefused() = %v, want %v", test.name, got, test.wantRefused)
+		}
+		if got := IsDNSError(test.err); got != test.wantDNS {
+			t.Errorf("%s: IsDNSError() = %v, want %v", test.name, got, test.wantDNS)
+		}
+		if got := IsTimeout(test.err); got != test.wantTimeout {
+			t.Errorf("%s: IsTimeout() = %v, want %v", test.name, got, test.wantTimeout)
+		}
+
+		classified := classifyNetworkError(test.err)
+		if test.wantKind == nil {
+			if classified != test.err {
+				t.Errorf("%s: classifyNetworkError() = %v, want the error unchanged", test.name, classified)
+			}
+			continue
+		}
+		if !errors.Is(classified, test.wantKind) || !errors.Is(classified, test.err) {
+			t.Errorf("%s: classifyNetworkError() = %v, want an error matching %v and the original error", test.name, classified, test.wantKind)
+		}
+	}
+}
+
+func TestClassifyNetworkErrorKeepsNetworkError(t *testing.T) {
+	// An error already classified isn't wrapped again, even if its Kind differs from what its original error would be classified as.
+	err := fmt.Errorf("query: %w", NetworkError{ErrTimeout, syscall.ECONNREFUSED})
+	if classified := classifyNetworkError(err); classified != err {
+		t.Errorf("classifyNetworkError() = %v, want the error unchanged", classified)
+	}
+}
@@ -0,0 +1,101 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+)
+
+const (
+	// faviconSchemePrefix begins the data URI that usually wraps the base64 encoded image in the favicon field.
+	faviconSchemePrefix string = "data:image/"
+	// faviconEncodingSuffix ends the media type of the data URI, separating it from the base64 encoded image.
+	faviconEncodingSuffix string = ";base64,"
+	// faviconSize is the width and height in pixels of a valid favicon.
+	faviconSize int = 64
+)
+
+// Errors.
+var (
+	// ErrInvalidFavicon is returned, wrapped with the reason, when the favicon isn't a 64x64 PNG.
+	ErrInvalidFavicon error = errors.New("invalid favicon")
+)
+
+// FaviconBytes decodes the favicon into the raw bytes of its image.
+//
+// The favicon may be sent as a "data:image/...;base64," data URI or as bare base64, as some servers leave out the scheme.
+// If the decoded favicon isn't a 64x64 PNG, the raw bytes are still returned along with an error wrapping ErrInvalidFavicon, so misconfigured favicons can be inspected.
+// nil is returned without an error if the server didn't send a favicon.
+// https://wiki.vg/Server_List_Ping#Status_Response
+func (s StatusResponse) FaviconBytes() ([]byte, error) {
+	if s.Favicon == "" {
+		return nil, nil
+	}
+
+	encoded, err := stripFaviconDataURI(s.Favicon)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older servers wrap the base64 encoded image across several lines.
+	encoded = strings.NewReplacer("\r", "", "\n", "").Replace(encoded)
+
+	favicon, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: favicon is not base64 encoded: %v", ErrInvalidFavicon, err)
+	}
+
+	_, _, err = faviconDimensions(favicon)
+	if err != nil {
+		return favicon, err
+	}
+
+	return favicon, nil
+}
+
+// stripFaviconDataURI returns the base64 encoded image of the favicon, removing the data URI scheme only if it is present.
+func stripFaviconDataURI(favicon string) (string, error) {
+	if !strings.HasPrefix(favicon, "data:") {
+		return favicon, nil
+	}
+
+	if !strings.HasPrefix(favicon, faviconSchemePrefix) {
+		return "", fmt.Errorf("%w: favicon data URI is not an image", ErrInvalidFavicon)
+	}
+
+	separator := strings.Index(favicon, faviconEncodingSuffix)
+	if separator == -1 {
+		return "", fmt.Errorf("%w: favicon data URI is not base64 encoded", ErrInvalidFavicon)
+	}
+
+	return favicon[separator+len(faviconEncodingSuffix):], nil
+}
+
+// FaviconDimensions returns the width and height in pixels of the favicon.
+//
+// An error wrapping ErrInvalidFavicon is returned if the favicon isn't a 64x64 PNG, along with its dimensions if it is a PNG of another size.
+func (s StatusResponse) FaviconDimensions() (int, int, error) {
+	favicon, err := s.FaviconBytes()
+	if favicon == nil {
+		return 0, 0, err
+	}
+
+	return faviconDimensions(favicon)
+}
+
+// faviconDimensions reads the dimensions from the header of the favicon and checks that it is a 64x64 PNG.
+func faviconDimensions(favicon []byte) (int, int, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(favicon))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: favicon is not a valid PNG: %v", ErrInvalidFavicon, err)
+	}
+
+	if config.Width != faviconSize || config.Height != faviconSize {
+		return config.Width, config.Height, fmt.Errorf("%w: favicon is %dx%d instead of %dx%d", ErrInvalidFavicon, config.Width, config.Height, faviconSize, faviconSize)
+	}
+
+	return config.Width, config.Height, nil
+}
@@ -0,0 +1,138 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// testPNG encodes a blank PNG of the given dimensions.
+func testPNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	encoded := &bytes.Buffer{}
+	if err := png.Encode(encoded, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	return encoded.Bytes()
+}
+
+// faviconDataURI wraps image in the data URI servers send in the favicon field.
+func faviconDataURI(image []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+}
+
+func TestFavicon(t *testing.T) {
+	favicon := testPNG(t, faviconSize, faviconSize)
+	status := StatusResponse{Favicon: faviconDataURI(favicon)}
+
+	got, err := status.FaviconBytes()
+	if err != nil {
+		t.Fatalf("FaviconBytes: %v", err)
+	}
+	if !bytes.Equal(got, favicon) {
+		t.Error("FaviconBytes() didn't return the encoded image")
+	}
+
+	width, height, err := status.FaviconDimensions()
+	if width != faviconSize || height != faviconSize || err != nil {
+		t.Errorf("FaviconDimensions() = %d, %d, %v, want %d, %d, nil", width, height, err, faviconSize, faviconSize)
+	}
+}
+
+func TestFaviconWrongSize(t *testing.T) {
+	favicon := testPNG(t, 32, 16)
+	status := StatusResponse{Favicon: faviconDataURI(favicon)}
+
+	// The raw bytes are still returned so a misconfigured favicon can be inspected.
+	got, err := status.FaviconBytes()
+	if !errors.Is(err, ErrInvalidFavicon) {
+		t.Errorf("FaviconBytes() error = %v, want ErrInvalidFavicon", err)
+	}
+	if !bytes.Equal(got, favicon) {
+		t.Error("FaviconBytes() didn't return the raw bytes along with the error")
+	}
+
+	width, height, err := status.FaviconDimensions()
+	if width != 32 || height != 16 || !errors.Is(err, ErrInvalidFavicon) {
+		t.Errorf("FaviconDimensions() = %d, %d, %v, want 32, 16 and ErrInvalidFavicon", width, height, err)
+	}
+}
+
+func TestFaviconNotPNG(t *testing.T) {
+	favicon := []byte("GIF89a not really a png")
+	status := StatusResponse{Favicon: faviconDataURI(favicon)}
+
+	got, err := status.FaviconBytes()
+	if !errors.Is(err, ErrInvalidFavicon) || !bytes.Equal(got, favicon) {
+		t.Errorf("FaviconBytes() = %q, %v, want the raw bytes and ErrInvalidFavicon", got, err)
+	}
+
+	width, height, err := status.FaviconDimensions()
+	if width != 0 || height != 0 || !errors.Is(err, ErrInvalidFavicon) {
+		t.Errorf("FaviconDimensions() = %d, %d, %v, want 0, 0 and ErrInvalidFavicon", width, height, err)
+	}
+}
+
+func TestFaviconEmpty(t *testing.T) {
+	status := StatusResponse{}
+
+	got, err := status.FaviconBytes()
+	if got != nil || err != nil {
+		t.Errorf("FaviconBytes() = %q, %v, want nil, nil", got, err)
+	}
+
+	width, height, err := status.FaviconDimensions()
+	if width != 0 || height != 0 || err != nil {
+		t.Errorf("FaviconDimensions() = %d, %d, %v, want 0, 0, nil", width, height, err)
+	}
+}
+
+func TestFaviconDataURI(t *testing.T) {
+	favicon := testPNG(t, faviconSize, faviconSize)
+	encoded := base64.StdEncoding.EncodeToString(favicon)
+
+	// wrapped splits encoded across lines as older servers do.
+	wrapped := ""
+	for i := 0; i < len(encoded); i += 76 {
+		end := i + 76
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		wrapped += encoded[i:end] + "\r\n"
+	}
+
+	tests := []struct {
+		name    string
+		favicon string
+		want    []byte
+		wantErr bool
+	}{
+		{"data URI", "data:image/png;base64," + encoded, favicon, false},
+		{"bare base64", encoded, favicon, false},
+		{"line-wrapped base64", "data:image/png;base64," + wrapped, favicon, false},
+		// The scheme of another image type is stripped, leaving the PNG check to reject the image itself.
+		{"other image type", "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString([]byte("\xff\xd8\xff")), []byte("\xff\xd8\xff"), true},
+		{"other image type holding a PNG", "data:image/x-icon;base64," + encoded, favicon, false},
+		{"not an image", "data:text/plain;base64," + encoded, nil, true},
+		{"not base64 encoded", "data:image/png," + encoded, nil, true},
+		{"invalid base64", "data:image/png;base64,not*base64!", nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := StatusResponse{Favicon: test.favicon}.FaviconBytes()
+		if test.wantErr != (err != nil) {
+			t.Errorf("%s: FaviconBytes() error = %v, want error %v", test.name, err, test.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidFavicon) {
+			t.Errorf("%s: FaviconBytes() error = %v, want ErrInvalidFavicon", test.name, err)
+		}
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: FaviconBytes() = %d bytes, want %d", test.name, len(got), len(test.want))
+		}
+	}
+}
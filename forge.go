@@ -0,0 +1,189 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf16"
+)
+
+var (
+	// errInvalidForgeData is returned when the optimized forgeData payload can't be decoded, in which case the plain mods array is used instead.
+	errInvalidForgeData error = errors.New("invalid status response: forgeData payload can't be decoded")
+)
+
+// forgeDataInfo contains the forgeData sent by Forge servers running 1.13 or newer in place of modinfo.
+// https://wiki.vg/Minecraft_Forge_Handshake#Changes_in_Forge_1.13
+type forgeDataInfo struct {
+	Mods []struct {
+		ModID     string `json:"modId"`
+		ModMarker string `json:"modmarker"`
+	} `json:"mods"`
+	FMLNetworkVersion int `json:"fmlNetworkVersion"`
+	// D contains the optimized payload sent by 1.18 and newer Forge servers in place of the mods and channels arrays.
+	D string `json:"d"`
+}
+
+// packageForgeData packages the mods from the forgeData into status if the server didn't send modinfo.
+//
+// The optimized "d" payload is decoded if present. If it can't be decoded, the plain mods array is used instead.
+func packageForgeData(response []byte, status *StatusResponse) error {
+	var forgeInfo struct {
+		ForgeData *forgeDataInfo `json:"forgeData"`
+	}
+
+	err := json.Unmarshal(response, &forgeInfo)
+	if err != nil {
+		return err
+	}
+
+	forgeData := forgeInfo.ForgeData
+	if forgeData == nil || status.ModInfo.Type != "" {
+		return nil
+	}
+
+	status.ModInfo.Type = "FML" + strconv.Itoa(forgeData.FMLNetworkVersion)
+	status.ModInfo.NetworkVersion = strconv.Itoa(forgeData.FMLNetworkVersion)
+
+	if forgeData.D != "" {
+		modList, err := decodeForgeDataMods(forgeData.D)
+		if err == nil {
+			status.ModInfo.ModList = modList
+			return nil
+		}
+	}
+
+	modList := []Mod{}
+	for _, mod := range forgeData.Mods {
+		modList = append(modList, Mod{mod.ModID, mod.ModMarker})
+	}
+	status.ModInfo.ModList = modList
+
+	return nil
+}
+
+// decodeForgeDataMods decodes the mods from the optimized forgeData payload.
+//
+// Channels are read past, as only the mods are packaged. Mods that are ignored when only on the server have an empty version.
+func decodeForgeDataMods(d string) ([]Mod, error) {
+	payload, err := decodeForgeDataPayload(d)
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader(payload)
+
+	// Skip the truncated flag.
+	_, err = reader.ReadByte()
+	if err != nil {
+		return nil, errInvalidForgeData
+	}
+
+	var modCount uint16
+	err = binary.Read(reader, binary.BigEndian, &modCount)
+	if err != nil {
+		return nil, errInvalidForgeData
+	}
+
+	modList := []Mod{}
+	for i := 0; i < int(modCount); i++ {
+		channelSizeAndVersionFlag, err := readVarIntFrom(reader)
+		if err != nil {
+			return nil, errInvalidForgeData
+		}
+		channelCount := channelSizeAndVersionFlag >> 1
+		ignoreServerOnly := channelSizeAndVersionFlag&1 != 0
+
+		mod := Mod{}
+		mod.Name, err = readForgeDataString(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !ignoreServerOnly {
+			mod.Version, err = readForgeDataString(reader)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Each channel contains its name, its version, and whether it is required on the client.
+		for j := 0; j < channelCount; j++ {
+			for k := 0; k < 2; k++ {
+				_, err = readForgeDataString(reader)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			_, err = reader.ReadByte()
+			if err != nil {
+				return nil, errInvalidForgeData
+			}
+		}
+
+		modList = append(modList, mod)
+	}
+
+	return modList, nil
+}
+
+// decodeForgeDataPayload converts the optimized forgeData string back into its bytes.
+//
+// The first two characters contain the size of the payload and every following character contains 15 bits of the payload.
+func decodeForgeDataPayload(d string) ([]byte, error) {
+	characters := utf16.Encode([]rune(d))
+	if len(characters) < 2 {
+		return nil, errInvalidForgeData
+	}
+
+	size := int(characters[0]) | int(characters[1])<<15
+	if size > len(characters)*2 {
+		return nil, errInvalidForgeData
+	}
+
+	payload := make([]byte, 0, size)
+	buffer := 0
+	bitsInBuffer := 0
+
+	for _, character := range characters[2:] {
+		for bitsInBuffer >= 8 {
+			payload = append(payload, byte(buffer))
+			buffer >>= 8
+			bitsInBuffer -= 8
+		}
+
+		buffer |= int(character&0x7FFF) << bitsInBuffer
+		bitsInBuffer += 15
+	}
+
+	// Write the bits left in the buffer.
+	for len(payload) < size {
+		if bitsInBuffer <= 0 {
+			return nil, errInvalidForgeData
+		}
+
+		payload = append(payload, byte(buffer))
+		buffer >>= 8
+		bitsInBuffer -= 8
+	}
+
+	return payload[:size], nil
+}
+
+// readForgeDataString reads a string prepended with a varint containing its length from the forgeData payload.
+func readForgeDataString(reader *bytes.Reader) (string, error) {
+	length, err := readVarIntFrom(reader)
+	if err != nil || length < 0 || length > reader.Len() {
+		return "", errInvalidForgeData
+	}
+
+	s := make([]byte, length)
+	_, err = io.ReadFull(reader, s)
+	if err != nil {
+		return "", errInvalidForgeData
+	}
+
+	return string(s), nil
+}
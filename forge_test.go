@@ -0,0 +1,74 @@
+package mcstatusgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForgeDataPayload(t *testing.T) {
+	// The payload follows the encoding of ServerStatusPing in Forge 40 for Minecraft 1.18.2, including a server-only mod without a version and mods with channels.
+	status := parseTestDocument(t, readTestData(t, "forge_1.18.2.json"))
+
+	want := []Mod{
+		{"minecraft", "1.18.2"},
+		{"forge", "40.2.0"},
+		{"jei", "9.7.2.1001"},
+		{"spark", ""},
+		{"journeymap", "1.18.2-5.9.7"},
+	}
+	if !reflect.DeepEqual(status.ModInfo.ModList, want) {
+		t.Errorf("ModList = %v, want %v", status.ModInfo.ModList, want)
+	}
+	if status.ModInfo.Type != "FML3" || status.ModInfo.NetworkVersion != "3" {
+		t.Errorf("ModInfo = %q (network version %q), want FML3 (3)", status.ModInfo.Type, status.ModInfo.NetworkVersion)
+	}
+}
+
+func TestForgeDataFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		forgeData string
+	}{
+		// Forge servers older than 1.18 only send the plain mods array.
+		{"plain mods array", `{"channels":[],"mods":[{"modId":"forge","modmarker":"36.2.39"},{"modId":"jei","modmarker":"7.7.1.153"}],"fmlNetworkVersion":2}`},
+		{"undecodable payload", `{"mods":[{"modId":"forge","modmarker":"36.2.39"},{"modId":"jei","modmarker":"7.7.1.153"}],"fmlNetworkVersion":2,"d":"d\u0000\u0001"}`},
+	}
+
+	want := []Mod{{"forge", "36.2.39"}, {"jei", "7.7.1.153"}}
+	for _, test := range tests {
+		status := parseTestDocument(t, `{"version":{"name":"1.16.5","protocol":754},"players":{"max":20,"online":0},"description":"","forgeData":`+test.forgeData+`}`)
+		if !reflect.DeepEqual(status.ModInfo.ModList, want) {
+			t.Errorf("%s: ModList = %v, want %v", test.name, status.ModInfo.ModList, want)
+		}
+	}
+}
+
+func TestDecodeForgeDataModsInvalid(t *testing.T) {
+	for _, d := range []string{"", "\u0001", "d\u0000\u0001", "\u0004\u0000\u0000Ā"} {
+		_, err := decodeForgeDataMods(d)
+		if err != errInvalidForgeData {
+			t.Errorf("decodeForgeDataMods(%q) error = %v, want errInvalidForgeData", d, err)
+		}
+	}
+}
+
+func TestLegacyModInfoOrder(t *testing.T) {
+	// The mods of a 1.12.2 Forge server are kept in the order sent, which isn't alphabetical.
+	status := parseTestDocument(t, readTestData(t, "forge_1.12.2.json"))
+
+	want := []Mod{
+		{"minecraft", "1.12.2"},
+		{"mcp", "9.42"},
+		{"FML", "8.0.99.99"},
+		{"forge", "14.23.5.2860"},
+		{"zzzcompat", "1.0"},
+		{"jei", "4.16.1.302"},
+		{"appliedenergistics2", "rv6-stable-7"},
+	}
+	if !reflect.DeepEqual(status.ModInfo.ModList, want) {
+		t.Errorf("ModList = %v, want %v", status.ModInfo.ModList, want)
+	}
+	if status.ModInfo.Type != "FML" || status.ModInfo.NetworkVersion != "2" {
+		t.Errorf("ModInfo = %q (network version %q), want FML (2)", status.ModInfo.Type, status.ModInfo.NetworkVersion)
+	}
+}
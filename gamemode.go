@@ -0,0 +1,48 @@
+package mcstatusgo
+
+import "strings"
+
+// GameMode contains a game mode normalized across protocols.
+type GameMode string
+
+// Game modes.
+const (
+	// GameModeSurvival identifies the survival game mode.
+	GameModeSurvival GameMode = "survival"
+	// GameModeCreative identifies the creative game mode.
+	GameModeCreative GameMode = "creative"
+	// GameModeAdventure identifies the adventure game mode.
+	GameModeAdventure GameMode = "adventure"
+	// GameModeSpectator identifies the spectator game mode.
+	GameModeSpectator GameMode = "spectator"
+	// GameModeSMP identifies the 'SMP' game type reported by Java servers through the query protocol, which doesn't specify a game mode.
+	GameModeSMP GameMode = "smp"
+	// GameModeUnknown identifies a game mode or game type that isn't recognized.
+	GameModeUnknown GameMode = "unknown"
+)
+
+// gameModes maps the lowercased game modes and game mode IDs sent by servers to their normalized GameMode.
+var gameModes = map[string]GameMode{
+	"survival":  GameModeSurvival,
+	"0":         GameModeSurvival,
+	"creative":  GameModeCreative,
+	"1":         GameModeCreative,
+	"adventure": GameModeAdventure,
+	"2":         GameModeAdventure,
+	"spectator": GameModeSpectator,
+	"3":         GameModeSpectator,
+	"smp":       GameModeSMP,
+}
+
+// NormalizeGameMode converts a game mode or game type sent by a server into its normalized GameMode.
+//
+// Matching is case-insensitive and numeric game mode IDs are accepted.
+// GameModeUnknown is returned if gameMode isn't recognized, which for the query protocol means the server reported a game type other than 'SMP'.
+func NormalizeGameMode(gameMode string) GameMode {
+	normalized, ok := gameModes[strings.ToLower(strings.TrimSpace(gameMode))]
+	if !ok {
+		return GameModeUnknown
+	}
+
+	return normalized
+}
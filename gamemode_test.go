@@ -0,0 +1,31 @@
+package mcstatusgo
+
+import "testing"
+
+func TestNormalizeGameMode(t *testing.T) {
+	tests := []struct {
+		gameMode string
+		want     GameMode
+	}{
+		{"survival", GameModeSurvival},
+		{"Creative", GameModeCreative},
+		{"ADVENTURE", GameModeAdventure},
+		{"  spectator\n", GameModeSpectator},
+		{"0", GameModeSurvival},
+		{"1", GameModeCreative},
+		{"2", GameModeAdventure},
+		{" 3 ", GameModeSpectator},
+		// Java servers report 'SMP' as the game type through the query protocol.
+		{"SMP", GameModeSMP},
+		{"smp", GameModeSMP},
+		{"4", GameModeUnknown},
+		{"hardcore", GameModeUnknown},
+		{"", GameModeUnknown},
+	}
+
+	for _, test := range tests {
+		if got := NormalizeGameMode(test.gameMode); got != test.want {
+			t.Errorf("NormalizeGameMode(%q) = %q, want %q", test.gameMode, got, test.want)
+		}
+	}
+}
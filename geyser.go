@@ -0,0 +1,23 @@
+package mcstatusgo
+
+import (
+	"strings"
+)
+
+const (
+	// geyserVersionPrefix begins the version name reported by servers fronted by Geyser.
+	geyserVersionPrefix string = "geyser"
+)
+
+// IsGeyser returns whether the server is fronted by Geyser, the proxy that lets Bedrock Edition clients join Java Edition servers, detected by a version name beginning with "Geyser".
+//
+// Only the player counts and description are reliable for these servers.
+// The version name contains the Geyser version rather than the Minecraft version, the player sample and ModInfo are left empty,
+// and the protocol version may not match any Java Edition release.
+// Players joining through Floodgate are only distinguishable by the prefix of their username, which depends on the server's configuration.
+// https://geysermc.org/
+func (s StatusResponse) IsGeyser() bool {
+	name := strings.ToLower(strings.TrimSpace(s.Version.Name))
+
+	return strings.HasPrefix(name, geyserVersionPrefix)
+}
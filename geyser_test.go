@@ -0,0 +1,28 @@
+package mcstatusgo
+
+import "testing"
+
+func TestIsGeyser(t *testing.T) {
+	tests := []struct {
+		versionName string
+		want        bool
+	}{
+		{"Geyser 2.2.0", true},
+		{"Geyser (git-master-4a1f1d8) 2.1.0", true},
+		{"geyser 2.2.0", true},
+		{" Geyser 2.2.0", true},
+		{"1.20.4", false},
+		{"Paper 1.20.4", false},
+		{"Velocity 3.1.2 1.20.4 (Geyser)", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		status := StatusResponse{}
+		status.Version.Name = test.versionName
+
+		if got := status.IsGeyser(); got != test.want {
+			t.Errorf("IsGeyser() with version name %q = %v, want %v", test.versionName, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+)
+
+// Hooks contains optional callbacks fired at key points of a request, which is useful for feeding the phases of a request into tracing such as OpenTelemetry spans.
+//
+// Any nil callback is skipped. Hooks are also how an Observer given by WithObserver is notified.
+type Hooks struct {
+	// OnDial is called after the connection to addr is established, with the duration of time taken to connect.
+	OnDial func(addr net.Addr, duration time.Duration)
+
+	// OnHandshake is called after the handshake packet of the request is sent, with the number of bytes sent and the duration of time taken to send them.
+	//
+	// The PROXY protocol header sent by WithProxyProtocol isn't the handshake, and neither is a query request reusing a cached challenge token, which sends no handshake at all.
+	OnHandshake func(protocol StatusProtocol, bytes int, duration time.Duration)
+
+	// OnRead is called after each read from the connection, with the number of bytes read and the duration of time taken by the read.
+	OnRead func(protocol StatusProtocol, bytes int, duration time.Duration)
+
+	// OnRetry is called when the request is retried after err, such as when a cached challenge token is rejected.
+	OnRetry func(protocol StatusProtocol, err error)
+
+	// OnResponse is called after a valid response is received, with the number of bytes received and the duration of time taken by the request after connecting.
+	OnResponse func(protocol StatusProtocol, bytes int, duration time.Duration)
+
+	// OnError is called when the request fails, including when it fails to connect.
+	OnError func(protocol StatusProtocol, err error)
+}
+
+// hooksConn fires the OnHandshake and OnRead hooks for the traffic of the net.Conn it wraps.
+type hooksConn struct {
+	net.Conn
+	hooks    Hooks
+	protocol StatusProtocol
+	// handshakeSent is set after the handshake is written, so the hook fires only once.
+	handshakeSent *bool
+}
+
+// Read reads from the wrapped net.Conn and fires the OnRead hook.
+func (c hooksConn) Read(b []byte) (int, error) {
+	startTime := time.Now()
+	bytesRead, err := c.Conn.Read(b)
+
+	if c.hooks.OnRead != nil {
+		c.hooks.OnRead(c.protocol, bytesRead, time.Since(startTime))
+	}
+
+	return bytesRead, err
+}
+
+// Write writes to the wrapped net.Conn and fires the OnHandshake hook if b is the handshake packet.
+func (c hooksConn) Write(b []byte) (int, error) {
+	startTime := time.Now()
+	bytesWritten, err := c.Conn.Write(b)
+
+	if !*c.handshakeSent && err == nil && isHandshakePacket(c.protocol, b) {
+		*c.handshakeSent = true
+		if c.hooks.OnHandshake != nil {
+			c.hooks.OnHandshake(c.protocol, bytesWritten, time.Since(startTime))
+		}
+	}
+
+	return bytesWritten, err
+}
+
+// isHandshakePacket reports whether the packet written by protocol is its handshake.
+//
+// The query protocols send their handshake only when no cached challenge token is used, so it is told apart by its type byte.
+// The status protocols send their handshake first, but may be preceded by the PROXY protocol header.
+func isHandshakePacket(protocol StatusProtocol, packet []byte) bool {
+	if protocol == ProtocolBasicQuery || protocol == ProtocolFullQuery {
+		return len(packet) > len(magicBytes) && bytes.HasPrefix(packet, magicBytes) && packet[len(magicBytes)] == handshakeByte
+	}
+
+	return !isProxyHeader(packet)
+}
+
+// chain returns hooks firing each callback of h followed by the same callback of next.
+func (h Hooks) chain(next Hooks) Hooks {
+	return Hooks{
+		OnDial:      chainDialHooks(h.OnDial, next.OnDial),
+		OnHandshake: chainTrafficHooks(h.OnHandshake, next.OnHandshake),
+		OnRead:      chainTrafficHooks(h.OnRead, next.OnRead),
+		OnRetry:     chainErrorHooks(h.OnRetry, next.OnRetry),
+		OnResponse:  chainTrafficHooks(h.OnResponse, next.OnResponse),
+		OnError:     chainErrorHooks(h.OnError, next.OnError),
+	}
+}
+
+// chainDialHooks returns a hook calling first and then second, skipping either if it is nil.
+func chainDialHooks(first func(net.Addr, time.Duration), second func(net.Addr, time.Duration)) func(net.Addr, time.Duration) {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+
+	return func(addr net.Addr, duration time.Duration) {
+		first(addr, duration)
+		second(addr, duration)
+	}
+}
+
+// chainTrafficHooks returns a hook calling first and then second, skipping either if it is nil.
+func chainTrafficHooks(first func(StatusProtocol, int, time.Duration), second func(StatusProtocol, int, time.Duration)) func(StatusProtocol, int, time.Duration) {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+
+	return func(protocol StatusProtocol, bytes int, duration time.Duration) {
+		first(protocol, bytes, duration)
+		second(protocol, bytes, duration)
+	}
+}
+
+// chainErrorHooks returns a hook calling first and then second, skipping either if it is nil.
+func chainErrorHooks(first func(StatusProtocol, error), second func(StatusProtocol, error)) func(StatusProtocol, error) {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+
+	return func(protocol StatusProtocol, err error) {
+		first(protocol, err)
+		second(protocol, err)
+	}
+}
+
+// locked returns hooks calling each callback of h while holding mutex, so callbacks shared by concurrent requests are never called concurrently.
+func (h Hooks) locked(mutex *sync.Mutex) Hooks {
+	locked := Hooks{}
+	if h.OnDial != nil {
+		locked.OnDial = func(addr net.Addr, duration time.Duration) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			h.OnDial(addr, duration)
+		}
+	}
+	locked.OnHandshake = lockTrafficHook(mutex, h.OnHandshake)
+	locked.OnRead = lockTrafficHook(mutex, h.OnRead)
+	locked.OnRetry = lockErrorHook(mutex, h.OnRetry)
+	locked.OnResponse = lockTrafficHook(mutex, h.OnResponse)
+	locked.OnError = lockErrorHook(mutex, h.OnError)
+
+	return locked
+}
+
+// lockTrafficHook returns a hook calling hook while holding mutex, or nil if hook is nil.
+func lockTrafficHook(mutex *sync.Mutex, hook func(StatusProtocol, int, time.Duration)) func(StatusProtocol, int, time.Duration) {
+	if hook == nil {
+		return nil
+	}
+
+	return func(protocol StatusProtocol, bytes int, duration time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		hook(protocol, bytes, duration)
+	}
+}
+
+// lockErrorHook returns a hook calling hook while holding mutex, or nil if hook is nil.
+func lockErrorHook(mutex *sync.Mutex, hook func(StatusProtocol, error)) func(StatusProtocol, error) {
+	if hook == nil {
+		return nil
+	}
+
+	return func(protocol StatusProtocol, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		hook(protocol, err)
+	}
+}
+
+// hookDial fires the OnDial hook if it was set.
+func (o options) hookDial(addr net.Addr, duration time.Duration) {
+	if o.hooks.OnDial == nil {
+		return
+	}
+
+	o.hooks.OnDial(addr, duration)
+}
+
+// hookError fires the OnError hook if it was set.
+func (o options) hookError(protocol StatusProtocol, err error) {
+	if o.hooks.OnError == nil {
+		return
+	}
+
+	o.hooks.OnError(protocol, err)
+}
+
+// hookOutcome fires the OnResponse or OnError hook for the outcome of the request if it was set.
+func (o options) hookOutcome(protocol StatusProtocol, stats *Stats, duration time.Duration, err error) {
+	if err != nil {
+		o.hookError(protocol, err)
+		return
+	}
+
+	if o.hooks.OnResponse != nil {
+		o.hooks.OnResponse(protocol, stats.BytesReceived, duration)
+	}
+}
+
+// hookRetry fires the OnRetry hook if it was set.
+func (o options) hookRetry(protocol StatusProtocol, err error) {
+	if o.hooks.OnRetry == nil {
+		return
+	}
+
+	o.hooks.OnRetry(protocol, err)
+}
@@ -0,0 +1,132 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// recordingConn records every write without sending it anywhere.
+type recordingConn struct {
+	net.Conn
+	writes [][]byte
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.writes = append(c.writes, append([]byte(nil), b...))
+
+	return len(b), nil
+}
+
+func TestHooksHandshakeWithProxyProtocol(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	for _, version := range []ProxyProtocolVersion{ProxyProtocolV1, ProxyProtocolV2} {
+		handshakes := []int{}
+		hooks := Hooks{
+			OnHandshake: func(protocol StatusProtocol, bytes int, duration time.Duration) {
+				handshakes = append(handshakes, bytes)
+			},
+		}
+
+		_, err := Status("127.0.0.1", port, testTimeout, testTimeout, WithHooks(hooks), WithProxyProtocol(version, nil, nil))
+		if err != nil {
+			t.Fatalf("v%d: Status: %v", version, err)
+		}
+
+		// The handshake and status request are written together, after the PROXY header.
+		want := len(createStatusHandshakePacket(protocolVersion, "127.0.0.1", port)) + len(statusRequestPacket)
+		if len(handshakes) != 1 || handshakes[0] != want {
+			t.Errorf("v%d: OnHandshake fired with %v bytes, want once with %d", version, handshakes, want)
+		}
+	}
+}
+
+func TestIsHandshakePacket(t *testing.T) {
+	proxyV1 := createProxyV1Header(&net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}, &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 2})
+	proxyV2 := createProxyV2Header(nil, nil)
+	sessionID := []byte{0x01, 0x02, 0x03, 0x04}
+	token := []byte{0x00, 0x00, 0x00, 0x01}
+
+	tests := []struct {
+		name     string
+		protocol StatusProtocol
+		packet   []byte
+		want     bool
+	}{
+		{"status handshake", ProtocolStatus, createStatusHandshakePacket(protocolVersion, "localhost", 25565), true},
+		{"proxy v1 header", ProtocolStatus, proxyV1, false},
+		{"proxy v2 header", ProtocolStatusLegacy, proxyV2, false},
+		{"beta request", ProtocolStatusBeta, []byte{betaRequestPacket}, true},
+		{"query handshake", ProtocolBasicQuery, createQueryHandshakePacket(sessionID), true},
+		{"query request with cached token", ProtocolFullQuery, createQueryRequestPacket(sessionID, token, true), false},
+	}
+
+	for _, test := range tests {
+		if got := isHandshakePacket(test.protocol, test.packet); got != test.want {
+			t.Errorf("%s: isHandshakePacket() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestHooksConnFiresOnce(t *testing.T) {
+	recorder := &recordingConn{}
+	fired := 0
+	con := hooksConn{recorder, Hooks{OnHandshake: func(StatusProtocol, int, time.Duration) { fired++ }}, ProtocolStatus, new(bool)}
+
+	con.Write(proxyV2Signature)
+	con.Write([]byte{0x00})
+	con.Write([]byte{0x00})
+
+	if fired != 1 {
+		t.Errorf("OnHandshake fired %d times, want 1", fired)
+	}
+	if !bytes.Equal(recorder.writes[0], proxyV2Signature) {
+		t.Errorf("first write = % x, want the PROXY header", recorder.writes[0])
+	}
+}
+
+// countingObserver counts the notifications of each kind and records the bytes of the last response.
+type countingObserver struct {
+	connects  int
+	responses int
+	errors    int
+	bytes     int
+}
+
+func (o *countingObserver) OnConnect(net.Addr, time.Duration) { o.connects++ }
+
+func (o *countingObserver) OnResponse(protocol StatusProtocol, bytes int, duration time.Duration) {
+	o.responses++
+	o.bytes = bytes
+}
+
+func (o *countingObserver) OnError(StatusProtocol, error) { o.errors++ }
+
+func TestObserverWithHooks(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	observer := &countingObserver{}
+	dials := 0
+	responseBytes := 0
+	hooks := Hooks{
+		OnDial:     func(net.Addr, time.Duration) { dials++ },
+		OnResponse: func(protocol StatusProtocol, bytes int, duration time.Duration) { responseBytes = bytes },
+	}
+
+	_, err := Status("127.0.0.1", port, testTimeout, testTimeout, WithObserver(observer), WithHooks(hooks), WithObserver(nil))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	if observer.connects != 1 || observer.responses != 1 || observer.errors != 0 {
+		t.Errorf("observer notified of %d connects, %d responses, and %d errors, want 1, 1, and 0", observer.connects, observer.responses, observer.errors)
+	}
+	if dials != 1 {
+		t.Errorf("OnDial fired %d times, want 1", dials)
+	}
+	if responseBytes == 0 || responseBytes != observer.bytes {
+		t.Errorf("OnResponse received %d bytes and the observer %d, want the same nonzero count", responseBytes, observer.bytes)
+	}
+}
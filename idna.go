@@ -0,0 +1,43 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/idna"
+)
+
+// Errors.
+var (
+	// ErrInvalidHostname is returned when an internationalized hostname can't be converted into its ASCII form.
+	ErrInvalidHostname error = errors.New("invalid hostname: hostname can't be converted into its ASCII form")
+)
+
+// toASCIIHostname converts an internationalized hostname into its ASCII form for DNS resolution.
+//
+// Non-ASCII hostnames are mapped and normalized following UTS #46 before each label is punycode encoded, so composed and decomposed forms of the same name produce the same A-labels.
+// Hostnames that are already ASCII (including IP addresses) are returned unchanged.
+// https://www.unicode.org/reports/tr46/
+func toASCIIHostname(hostname string) (string, error) {
+	if isASCII(hostname) {
+		return hostname, nil
+	}
+
+	asciiHostname, err := idna.Lookup.ToASCII(hostname)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidHostname, err)
+	}
+
+	return asciiHostname, nil
+}
+
+// isASCII reports whether s only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
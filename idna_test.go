@@ -0,0 +1,103 @@
+package mcstatusgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToASCIIHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		// The decomposed form, with a combining diaeresis, is normalized first.
+		{"mu\u0308nchen.de", "xn--mnchen-3ya.de"},
+		{"MÜNCHEN.de", "xn--mnchen-3ya.de"},
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"mc.example.com", "mc.example.com"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"::1", "::1"},
+	}
+
+	for _, test := range tests {
+		got, err := toASCIIHostname(test.hostname)
+		if err != nil {
+			t.Errorf("toASCIIHostname(%q): %v", test.hostname, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("toASCIIHostname(%q) = %q, want %q", test.hostname, got, test.want)
+		}
+	}
+}
+
+func TestToASCIIHostnameInvalid(t *testing.T) {
+	_, err := toASCIIHostname("bad\u200d.example")
+	if !errors.Is(err, ErrInvalidHostname) {
+		t.Errorf("toASCIIHostname() error = %v, want ErrInvalidHostname", err)
+	}
+}
+
+func TestDialPunycode(t *testing.T) {
+	queried := make(chan string, 1)
+
+	// The resolver sends its DNS query over a pipe, from which the queried name is read.
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go func() {
+				defer server.Close()
+				query := make([]byte, 512)
+				bytesRead, _ := server.Read(query)
+				select {
+				case queried <- dnsQueryName(query[:bytesRead]):
+				default:
+				}
+			}()
+
+			return client, nil
+		},
+	}
+
+	_, err := Status("münchen.test", 25565, time.Second, time.Second, WithResolver(resolver))
+	if err == nil {
+		t.Fatal("Status() succeeded without a DNS answer")
+	}
+
+	select {
+	case name := <-queried:
+		if name != "xn--mnchen-3ya.test" {
+			t.Errorf("dialed %q, want %q", name, "xn--mnchen-3ya.test")
+		}
+	default:
+		t.Fatal("no DNS query was sent")
+	}
+}
+
+// dnsQueryName extracts the question name from a DNS query sent over a stream, which is prefixed with its length.
+func dnsQueryName(query []byte) string {
+	// Skip the length prefix and the header.
+	const questionOffset = 2 + 12
+	if len(query) <= questionOffset {
+		return ""
+	}
+
+	labels := []string{}
+	for i := questionOffset; i < len(query) && query[i] != 0; {
+		length := int(query[i])
+		if i+1+length > len(query) {
+			return ""
+		}
+		labels = append(labels, string(query[i+1:i+1+length]))
+		i += 1 + length
+	}
+
+	return strings.Join(labels, ".")
+}
@@ -0,0 +1,26 @@
+package mcstatusgo
+
+import "time"
+
+// latencyTimer measures the latency between sending a request and receiving its response.
+//
+// The latency is calculated from the monotonic clock reading of each time, so it isn't affected by changes to the wall clock.
+type latencyTimer struct {
+	now   func() time.Time
+	start time.Time
+}
+
+// startLatencyTimer starts measuring latency with the clock of the request and should be called immediately before the request is sent.
+func (o options) startLatencyTimer() latencyTimer {
+	now := o.clock
+	if now == nil {
+		now = time.Now
+	}
+
+	return latencyTimer{now: now, start: now()}
+}
+
+// elapsed returns the latency measured since the timer started and should be called immediately after the response is received.
+func (t latencyTimer) elapsed() time.Duration {
+	return t.now().Sub(t.start)
+}
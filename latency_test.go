@@ -0,0 +1,46 @@
+package mcstatusgo
+
+import (
+	"testing"
+	"time"
+)
+
+// withFakeClock measures latency with a clock that advances by step each time it is read, in place of time.Now.
+func withFakeClock(step time.Duration) Option {
+	return func(o *options) {
+		current := time.Unix(1700000000, 0)
+		o.clock = func() time.Time {
+			current = current.Add(step)
+			return current
+		}
+	}
+}
+
+func TestLatencyTimer(t *testing.T) {
+	timer := newOptions([]Option{withFakeClock(40 * time.Millisecond)}).startLatencyTimer()
+	if latency := timer.elapsed(); latency != 40*time.Millisecond {
+		t.Errorf("elapsed() = %v, want 40ms", latency)
+	}
+}
+
+func TestStatusLatency(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	// The clock is read once when the ping is sent and once when the pong is received.
+	status, err := Status("127.0.0.1", port, testTimeout, testTimeout, withFakeClock(25*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Latency != 25*time.Millisecond {
+		t.Errorf("Latency = %v, want exactly 25ms", status.Latency)
+	}
+
+	// Without the ping, the clock is read when the request is sent and when the first byte of the response is received.
+	status, err = Status("127.0.0.1", port, testTimeout, testTimeout, WithSkipPing(), withFakeClock(25*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Latency != 25*time.Millisecond {
+		t.Errorf("Latency with WithSkipPing = %v, want exactly 25ms", status.Latency)
+	}
+}
@@ -0,0 +1,30 @@
+package mcstatusgo
+
+import (
+	"net"
+	"time"
+)
+
+// Observer is notified at the key points of a request.
+//
+// Exactly one of OnResponse or OnError is called for each request, including requests that fail to connect.
+// An observer is fired through the same callbacks as Hooks, see WithObserver.
+type Observer interface {
+	// OnConnect is called after the connection to addr is established, with the duration of time taken to connect.
+	OnConnect(addr net.Addr, duration time.Duration)
+
+	// OnResponse is called after a valid response is received, with the number of bytes received and the duration of time taken by the request after connecting.
+	OnResponse(protocol StatusProtocol, bytes int, duration time.Duration)
+
+	// OnError is called when the request fails.
+	OnError(protocol StatusProtocol, err error)
+}
+
+// observerHooks converts observer into the equivalent hooks.
+func observerHooks(observer Observer) Hooks {
+	return Hooks{
+		OnDial:     observer.OnConnect,
+		OnResponse: observer.OnResponse,
+		OnError:    observer.OnError,
+	}
+}
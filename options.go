@@ -0,0 +1,641 @@
+package mcstatusgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxResponseSize is the largest packet size allowed by the Minecraft protocol, used as the maximum response size unless WithMaxResponseSize is given.
+	// https://wiki.vg/Protocol#Packet_format
+	DefaultMaxResponseSize int = 2097151
+	// DefaultPlayerSampleLimit is the maximum number of player sample entries parsed from a status response unless WithPlayerSampleLimit is given.
+	DefaultPlayerSampleLimit int = 1000
+	// DefaultPongTimeout is the longest the status protocol waits for the pong unless WithPongTimeout is given, as the ping is only used to measure latency.
+	DefaultPongTimeout time.Duration = 2 * time.Second
+)
+
+const (
+	// statusReadBufferSize is the default size of the buffered reader of the status protocol.
+	statusReadBufferSize int = 4096
+	// queryReadBufferSize is the default size of the buffer the query response datagram is read into.
+	queryReadBufferSize int = 8192
+	// betaReadBufferSize is the default size of the buffer each read of the beta status response is received into.
+	betaReadBufferSize int = 32
+)
+
+// Option configures optional behavior of a request.
+//
+// Options that don't apply to a protocol are ignored by it.
+type Option func(*options)
+
+// options contains the optional settings used by a request.
+type options struct {
+	// hostname is the hostname sent in the status handshake and legacy request in place of the dialed server.
+	hostname string
+	// port is the port sent in the status handshake and legacy request in place of the dialed port.
+	port uint16
+	// protocolVersion replaces the protocol version sent in the status handshake if protocolVersionSet is true.
+	protocolVersion    int
+	protocolVersionSet bool
+	// forgeMarker is appended to the hostname sent in the status handshake.
+	forgeMarker ForgeMarker
+	// maxResponseSize is the largest response size the server is allowed to declare.
+	maxResponseSize int
+	// playerSampleLimit is the maximum number of player sample entries parsed from the status response.
+	playerSampleLimit int
+	// validatePlayers enables the validation of the player counts sent by the server.
+	validatePlayers bool
+	// stats receives the traffic statistics of the request.
+	stats *Stats
+	// skipPing disables the ping used to measure latency in the status protocol.
+	skipPing bool
+	// readBufferSize replaces the default read buffer size of each protocol if it is positive.
+	readBufferSize int
+	// pongTimeout replaces the io timeout of the ping in the status protocol.
+	pongTimeout time.Duration
+	// traceWriter receives a trace of each step of the request.
+	traceWriter io.Writer
+	// localAddr is the local address the connection is bound to.
+	localAddr net.Addr
+	// noDelay replaces the TCP_NODELAY setting of TCP connections if noDelaySet is true.
+	noDelay    bool
+	noDelaySet bool
+	// disableKeepAlive disables the keepalive probes of TCP connections.
+	disableKeepAlive bool
+	// network forces the IP version used to dial the server.
+	network string
+	// resolver resolves the server's hostname in place of net.DefaultResolver.
+	resolver *net.Resolver
+	// proxyProtocol is the version of the PROXY protocol header sent before the request, which isn't sent if it is 0.
+	proxyProtocol ProxyProtocolVersion
+	// proxySource and proxyDestination replace the addresses of the connection in the PROXY protocol header if they aren't nil.
+	proxySource      net.Addr
+	proxyDestination net.Addr
+	// tokenCache caches the challenge tokens received from query handshakes.
+	tokenCache *ChallengeTokenCache
+	// hooks are fired at key points of the request, including the callbacks of the observer given by WithObserver.
+	hooks Hooks
+	// ctx bounds the dial and the deadline of each io operation.
+	ctx context.Context
+	// overallTimeout bounds the whole status request, from dialing to receiving the pong.
+	overallTimeout time.Duration
+	// handshakeTimeout replaces the io timeout of the query handshake.
+	handshakeTimeout time.Duration
+	// responseTimeout replaces the io timeout of the query request and response.
+	responseTimeout time.Duration
+	// sessionID replaces the random session ID of the query request if sessionIDSet is true.
+	sessionID    uint32
+	sessionIDSet bool
+	// requiredQueryKeys replaces the keys a full query response must contain if requiredQueryKeysSet is true.
+	requiredQueryKeys    []string
+	requiredQueryKeysSet bool
+	// clock replaces time.Now for measuring latency, which lets tests substitute a fake clock.
+	clock func() time.Time
+}
+
+// newOptions applies opts over the default options.
+func newOptions(opts []Option) options {
+	o := options{
+		maxResponseSize:   DefaultMaxResponseSize,
+		playerSampleLimit: DefaultPlayerSampleLimit,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// handshakeHostname returns the hostname that should be sent in the status handshake.
+func (o options) handshakeHostname(server string) string {
+	return o.advertisedHostname(server) + string(o.forgeMarker)
+}
+
+// advertisedHostname returns the hostname the client claims to have connected to.
+func (o options) advertisedHostname(server string) string {
+	if o.hostname != "" {
+		return o.hostname
+	}
+
+	return server
+}
+
+// advertisedPort returns the port the client claims to have connected to.
+func (o options) advertisedPort(port uint16) uint16 {
+	if o.port != 0 {
+		return o.port
+	}
+
+	return port
+}
+
+// advertisedProtocolVersion returns the protocol version sent in the status handshake.
+func (o options) advertisedProtocolVersion() int {
+	if o.protocolVersionSet {
+		return o.protocolVersion
+	}
+
+	return protocolVersion
+}
+
+// readBufferSizeOr returns the read buffer size given with WithReadBufferSize, or defaultSize if none was given.
+func (o options) readBufferSizeOr(defaultSize int) int {
+	if o.readBufferSize > 0 {
+		return o.readBufferSize
+	}
+
+	return defaultSize
+}
+
+// validatePlayerCounts checks the player counts sent by the server if player count validation is enabled.
+func (o options) validatePlayerCounts(online int, max int) error {
+	if !o.validatePlayers {
+		return nil
+	}
+
+	return checkPlayerCounts(online, max)
+}
+
+// wrapConn wraps con to record its traffic into the returned stats, and to trace it or fire hooks if requested.
+func (o options) wrapConn(con net.Conn, protocol StatusProtocol) (net.Conn, *Stats) {
+	stats := &Stats{}
+	con = statsConn{con, stats}
+
+	if o.traceWriter != nil {
+		con = traceConn{con, o.traceWriter}
+	}
+
+	if o.hooks.OnHandshake != nil || o.hooks.OnRead != nil {
+		con = hooksConn{con, o.hooks, protocol, new(bool)}
+	}
+
+	if o.ctx != nil {
+		con = contextConn{con, o.ctx}
+	}
+
+	return con, stats
+}
+
+// queryHandshakeTimeout returns the io timeout used for the query handshake.
+func (o options) queryHandshakeTimeout(ioTimeout time.Duration) time.Duration {
+	if o.handshakeTimeout > 0 {
+		return o.handshakeTimeout
+	}
+
+	return ioTimeout
+}
+
+// queryResponseTimeout returns the io timeout used for the query request and response.
+func (o options) queryResponseTimeout(ioTimeout time.Duration) time.Duration {
+	if o.responseTimeout > 0 {
+		return o.responseTimeout
+	}
+
+	return ioTimeout
+}
+
+// statusPongTimeout returns the io timeout of the ping in the status protocol.
+func (o options) statusPongTimeout(ioTimeout time.Duration) time.Duration {
+	if o.pongTimeout > 0 {
+		return o.pongTimeout
+	}
+	if ioTimeout < DefaultPongTimeout {
+		return ioTimeout
+	}
+
+	return DefaultPongTimeout
+}
+
+// queryRequiredKeys returns the keys a full query response must contain.
+func (o options) queryRequiredKeys() []string {
+	if o.requiredQueryKeysSet {
+		return o.requiredQueryKeys
+	}
+
+	return defaultQueryRequiredKeys
+}
+
+// querySessionID returns the session ID used for a new query handshake.
+func (o options) querySessionID() []byte {
+	if o.sessionIDSet {
+		return sessionIDToBytes(o.sessionID)
+	}
+
+	return createSessionID()
+}
+
+// context returns the context of the request, which is context.Background if none was given.
+func (o options) context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+
+	return o.ctx
+}
+
+// withOverallTimeout bounds the context of o by the overall timeout starting now, if one was given.
+//
+// The returned cancel function must be called once the request is done.
+func (o options) withOverallTimeout() (options, context.CancelFunc) {
+	if o.overallTimeout <= 0 {
+		return o, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(o.context(), o.overallTimeout)
+	o.ctx = ctx
+
+	return o, cancel
+}
+
+// ipVersion returns the IP version forced by the network given with WithNetwork, which is empty if either version can be used.
+func (o options) ipVersion() (string, error) {
+	switch o.network {
+	case "", "tcp", "udp":
+		return "", nil
+	case "tcp4", "udp4":
+		return "4", nil
+	case "tcp6", "udp6":
+		return "6", nil
+	}
+
+	return "", fmt.Errorf("%w: unsupported network %q", ErrInvalidArgument, o.network)
+}
+
+// netResolver returns the resolver used to resolve the server's hostname.
+func (o options) netResolver() *net.Resolver {
+	if o.resolver != nil {
+		return o.resolver
+	}
+
+	return net.DefaultResolver
+}
+
+// keepAlive returns the keepalive period of the dialer, which is negative if keepalive is disabled and 0 to use the default.
+func (o options) keepAlive() time.Duration {
+	if o.disableKeepAlive {
+		return -1
+	}
+
+	return 0
+}
+
+// configureTCPConn applies the TCP_NODELAY setting to con if one was given and con is a TCP connection.
+func (o options) configureTCPConn(con net.Conn) error {
+	tcpCon, ok := con.(*net.TCPConn)
+	if !ok || !o.noDelaySet {
+		return nil
+	}
+
+	return tcpCon.SetNoDelay(o.noDelay)
+}
+
+// localAddrFor returns the local address to bind to in the form expected by the dialer for network.
+func (o options) localAddrFor(network string) net.Addr {
+	var ip net.IP
+	var port int
+	var zone string
+
+	switch addr := o.localAddr.(type) {
+	case *net.TCPAddr:
+		ip, port, zone = addr.IP, addr.Port, addr.Zone
+	case *net.UDPAddr:
+		ip, port, zone = addr.IP, addr.Port, addr.Zone
+	case *net.IPAddr:
+		ip, zone = addr.IP, addr.Zone
+	default:
+		// Leave any other address for the dialer to validate.
+		return o.localAddr
+	}
+
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: ip, Port: port, Zone: zone}
+	}
+
+	return &net.TCPAddr{IP: ip, Port: port, Zone: zone}
+}
+
+// trace writes a step of the request to the trace writer if a trace was requested.
+func (o options) trace(format string, args ...interface{}) {
+	if o.traceWriter == nil {
+		return
+	}
+
+	fmt.Fprintf(o.traceWriter, format+"\n", args...)
+}
+
+// traceOutcome writes the outcome of parsing the response of protocol to the trace writer if a trace was requested.
+func (o options) traceOutcome(protocol string, err error) {
+	if err != nil {
+		o.trace("%s parse failed: %v", protocol, err)
+		return
+	}
+
+	o.trace("%s parse succeeded", protocol)
+}
+
+// WithHostname sets the hostname sent in the status handshake and the legacy status request independently of the address that is dialed.
+//
+// Proxies such as BungeeCord and Velocity route connections based on this hostname, so it can be used to query a specific backend or test virtual-host routing.
+// If unset, the dialed server is sent.
+func WithHostname(hostname string) Option {
+	return func(o *options) {
+		o.hostname = hostname
+	}
+}
+
+// WithProtocolVersion sets the protocol version sent in the status handshake, which identifies the version of Minecraft the client claims to run.
+//
+// Along with WithHostname, it controls what the request advertises to the server, so scans can be made attributable or reproduce the fingerprint of a specific client.
+// Some servers also tailor the status response to the protocol version, such as reporting a version as incompatible.
+// Only the status handshake is affected, as the legacy request uses the protocol numbering of older versions.
+// ProtocolFromVersion converts a version name such as "1.20.4" into its protocol version.
+// https://wiki.vg/Protocol_version_numbers
+func WithProtocolVersion(version int) Option {
+	return func(o *options) {
+		o.protocolVersion = version
+		o.protocolVersionSet = true
+	}
+}
+
+// WithClientAddr sets the hostname and port the client claims to have connected to, independently of the address that is dialed.
+//
+// They are sent in the status handshake and in the MC|PingHost plugin message of the legacy status request, which some plugins and proxies inspect.
+// An empty hostname or a port of 0 leaves that value unchanged.
+func WithClientAddr(hostname string, port uint16) Option {
+	return func(o *options) {
+		if hostname != "" {
+			o.hostname = hostname
+		}
+		o.port = port
+	}
+}
+
+// WithMaxResponseSize sets the largest response size in bytes the server is allowed to declare before ErrResponseTooLarge is returned.
+//
+// The check happens before any of the response is read, protecting against servers that declare huge responses.
+// It applies to every length-prefixed response: the status response, kick packets, and the legacy and beta status responses.
+// If unset, DefaultMaxResponseSize is used.
+func WithMaxResponseSize(size int) Option {
+	return func(o *options) {
+		o.maxResponseSize = size
+	}
+}
+
+// WithReadBufferSize sets the size in bytes of the buffer responses are read into, replacing the default of each protocol.
+//
+// A larger buffer reduces the number of reads of large responses when scanning many servers, while a smaller one saves memory.
+// The defaults are 4096 bytes for the status protocol, 8192 bytes for the query protocols, and 32 bytes for the beta status protocol.
+// The query response is received in a single datagram, which is cut off by a smaller buffer.
+// A full query response that fills the buffer is therefore requested again with a buffer large enough for any datagram, at the cost of one more round trip.
+// The short basic query response fits in any reasonable buffer and isn't requested again.
+// A size that isn't positive keeps the defaults.
+func WithReadBufferSize(size int) Option {
+	return func(o *options) {
+		o.readBufferSize = size
+	}
+}
+
+// WithPlayerSampleLimit sets the maximum number of player sample entries parsed from the status response.
+//
+// Entries beyond the limit are skipped without being allocated and SampleTruncated is set, protecting against servers that send huge samples.
+// A negative limit parses every entry.
+// If unset, DefaultPlayerSampleLimit is used.
+func WithPlayerSampleLimit(limit int) Option {
+	return func(o *options) {
+		o.playerSampleLimit = limit
+	}
+}
+
+// WithPlayerCountValidation enables the validation of the player counts sent by the server.
+//
+// Some servers report negative or absurdly large online counts as an anti-scraping measure or due to a bug.
+// When enabled, ErrImplausiblePlayerCount is returned if either count is negative or the online count exceeds the max count more than tenfold.
+func WithPlayerCountValidation() Option {
+	return func(o *options) {
+		o.validatePlayers = true
+	}
+}
+
+// WithStats records the traffic statistics of the request into stats.
+//
+// stats is overwritten once the request finishes, even if it fails, but isn't touched if the server can't be connected to.
+// The BytesSent and BytesReceived fields of the response are filled in whether or not WithStats is given.
+func WithStats(stats *Stats) Option {
+	return func(o *options) {
+		o.stats = stats
+	}
+}
+
+// WithPongTimeout sets the io timeout of the ping sent after the status response is received, independently of the status response.
+//
+// If unset, the smaller of DefaultPongTimeout and the ioTimeout of the request is used.
+// Some antibot layers never answer the ping nor close the connection, so a short timeout keeps them from delaying the request.
+// A pong that doesn't arrive in time doesn't fail the request, see StatusResponse.PingErr.
+func WithPongTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.pongTimeout = timeout
+	}
+}
+
+// WithSkipPing disables the ping sent after the status response is received to measure latency.
+//
+// Some servers and proxies (such as TCPShield) answer the status request but never reply to the ping, causing the request to wait for the pong timeout.
+// When set, StatusResponse.Latency is the time to the first byte of the status response instead of the round trip of the ping.
+func WithSkipPing() Option {
+	return func(o *options) {
+		o.skipPing = true
+	}
+}
+
+// WithTrace writes a trace of each step of the request to w.
+//
+// The trace contains a hex dump of every packet sent and received, the challenge token received from the query handshake, the raw response length, and the parse outcome.
+// Nothing is traced when unset, so there is no overhead.
+func WithTrace(w io.Writer) Option {
+	return func(o *options) {
+		o.traceWriter = w
+	}
+}
+
+// WithLocalAddr binds the outgoing connection to addr, which is useful on multi-homed hosts or when monitoring from a specific egress IP.
+//
+// addr can be a *net.TCPAddr, *net.UDPAddr, or *net.IPAddr and is converted to the form needed by each protocol, so the same address applies to both the TCP status protocols and the UDP query protocols.
+// The binding applies to the connection to the address that is finally dialed, regardless of how the server's hostname was resolved.
+// A port of 0 lets the operating system choose the local port.
+func WithLocalAddr(addr net.Addr) Option {
+	return func(o *options) {
+		o.localAddr = addr
+	}
+}
+
+// WithNoDelay sets TCP_NODELAY on the connection of the TCP status protocols before the handshake.
+//
+// When true, Nagle's algorithm is disabled so small packets such as the ping are sent immediately, giving more accurate latency measurements.
+// Go already enables TCP_NODELAY by default, so this is mainly useful to state the setting explicitly or to turn it off.
+func WithNoDelay(noDelay bool) Option {
+	return func(o *options) {
+		o.noDelay = noDelay
+		o.noDelaySet = true
+	}
+}
+
+// WithoutKeepAlive disables the keepalive probes of the connection of the TCP status protocols, which are unnecessary for connections that only live for a single request.
+func WithoutKeepAlive() Option {
+	return func(o *options) {
+		o.disableKeepAlive = true
+	}
+}
+
+// WithNetwork forces the IP version used to dial the server, which is useful for measuring the IPv4 or IPv6 reachability of a dual-stack server.
+//
+// network can be "tcp4" or "tcp6" for the status protocols and "udp4" or "udp6" for the query protocols.
+// Only the IP version is taken from network, so "tcp6" also forces IPv6 for the query protocols and vice versa.
+// Hostnames resolved ahead of dialing, such as by Client, resolve to an address of the forced version.
+// An unsupported network causes the request to fail with ErrInvalidArgument.
+func WithNetwork(network string) Option {
+	return func(o *options) {
+		o.network = network
+	}
+}
+
+// WithResolver resolves the server's hostname using resolver in place of net.DefaultResolver, such as to query an internal nameserver for split-horizon names.
+//
+// It is used both when dialing and when a hostname is resolved ahead of dialing, such as by Client.
+func WithResolver(resolver *net.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithProxyProtocol sends a HAProxy PROXY protocol header of version before the request, for servers behind a proxy that expects one.
+//
+// The header relays a connection from source to destination, which default to the local and remote address of the connection if nil.
+// If they aren't *net.TCPAddr of the same IP version, a header without addresses is sent.
+// The header is written right after dialing and before the handshake, and is only sent by the TCP status protocols, never by the UDP query protocols.
+// https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt
+func WithProxyProtocol(version ProxyProtocolVersion, source net.Addr, destination net.Addr) Option {
+	return func(o *options) {
+		o.proxyProtocol = version
+		o.proxySource = source
+		o.proxyDestination = destination
+	}
+}
+
+// WithChallengeTokenCache reuses the challenge tokens cached in cache for query requests, skipping the query handshake while a token is valid.
+//
+// Servers tie each challenge token to the client's address, so tokens are cached by both the local and remote address of the connection.
+// A dialing query function such as FullQuery only gets a cache hit if WithLocalAddr binds a fixed local port, as each dial otherwise uses a new one.
+// See ChallengeTokenCache for when a cached token can be reused.
+func WithChallengeTokenCache(cache *ChallengeTokenCache) Option {
+	return func(o *options) {
+		o.tokenCache = cache
+	}
+}
+
+// WithObserver notifies observer of the connection and the outcome of each request, which is useful for instrumentation such as Prometheus metrics.
+//
+// The observer is fired as the OnDial, OnResponse, and OnError hooks, so it can be combined with WithHooks. A nil observer is ignored.
+func WithObserver(observer Observer) Option {
+	return func(o *options) {
+		if observer == nil {
+			return
+		}
+
+		o.hooks = o.hooks.chain(observerHooks(observer))
+	}
+}
+
+// WithHooks fires the callbacks in hooks at key points of each request.
+//
+// Unlike WithObserver, the hooks also cover the individual phases of the request, such as sending the handshake and each read.
+// If WithHooks or WithObserver is given more than once, the callbacks of each are fired in the order given.
+func WithHooks(hooks Hooks) Option {
+	return func(o *options) {
+		o.hooks = o.hooks.chain(hooks)
+	}
+}
+
+// WithContext bounds the request by ctx.
+//
+// Dialing stops when ctx is cancelled, and the deadline of each io operation is clamped to the deadline of ctx, so a short context isn't overridden by a longer ioTimeout.
+// The connection of a status request is also closed once ctx is cancelled, so a context without a deadline still interrupts it.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithOverallTimeout bounds the whole status request by timeout, covering the dial, the response, and the ping.
+//
+// The initial connection timeout and io timeout still apply to each step, but no step can run past the overall deadline.
+// It is applied on top of WithContext, so the earlier of the two deadlines wins.
+func WithOverallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.overallTimeout = timeout
+	}
+}
+
+// WithHandshakeTimeout sets the io timeout of the query handshake, which receives the small challenge token, independently of the stat response.
+//
+// If unset, the ioTimeout of the request is used.
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.handshakeTimeout = timeout
+	}
+}
+
+// WithResponseTimeout sets the io timeout of the query stat request and its response, which can be much larger than the handshake, independently of the handshake.
+//
+// If unset, the ioTimeout of the request is used.
+func WithResponseTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.responseTimeout = timeout
+	}
+}
+
+// WithSessionID replaces the random session ID of the query request with sessionID, which is useful for deterministic tests and auditing the exchange.
+//
+// The session ID is masked with 0x0F0F0F0F as the server expects, so the SessionID of the response is the masked value.
+// It is ignored if a cached challenge token is used, as the token is bound to the session ID it was received with.
+func WithSessionID(sessionID uint32) Option {
+	return func(o *options) {
+		o.sessionID = sessionID
+		o.sessionIDSet = true
+	}
+}
+
+// ForgeMarker is appended to the status handshake hostname to signal a Forge-aware client.
+// https://wiki.vg/Minecraft_Forge_Handshake
+type ForgeMarker string
+
+const (
+	// ForgeMarkerFML signals a Forge-aware client to servers running Forge for Minecraft 1.12.2 and older.
+	ForgeMarkerFML ForgeMarker = "\x00FML\x00"
+	// ForgeMarkerFML2 signals a Forge-aware client to servers running Forge for Minecraft 1.13 and newer.
+	ForgeMarkerFML2 ForgeMarker = "\x00FML2\x00"
+)
+
+// WithForgeMarker appends marker to the hostname sent in the status handshake.
+//
+// Some Forge servers only send their full mod information in StatusResponse.ModInfo when the client signals it is Forge-aware.
+func WithForgeMarker(marker ForgeMarker) Option {
+	return func(o *options) {
+		o.forgeMarker = marker
+	}
+}
+
+// WithQueryRequiredKeys replaces the keys a full query response must contain, otherwise ErrMissingInformation is returned.
+//
+// By default hostname, gametype, version, map, numplayers, and maxplayers are required, while game_id and plugins are optional.
+// Missing optional keys leave their fields empty. Passing no keys accepts any response.
+func WithQueryRequiredKeys(keys ...string) Option {
+	return func(o *options) {
+		o.requiredQueryKeys = append([]string{}, keys...)
+		o.requiredQueryKeysSet = true
+	}
+}
@@ -0,0 +1,94 @@
+package mcstatusgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// This file contains the offline parsers, which parse responses captured from a server without any network activity.
+// The IP, Port, and RemoteAddr fields of the parsed responses are left empty and Latency is -1.
+
+// ParseStatusResponse parses a status response captured from a server.
+//
+// raw must contain the response exactly as sent by the server, starting with the varint containing the length of the response.
+// At most DefaultPlayerSampleLimit player sample entries are parsed.
+// https://wiki.vg/Server_List_Ping#Response
+func ParseStatusResponse(raw []byte) (StatusResponse, error) {
+	reader := bufio.NewReader(bytes.NewReader(raw))
+
+	err := checkKickPacket(reader, len(raw))
+	if err != nil {
+		return StatusResponse{}, shortResponseError(err, ErrShortStatusResponse)
+	}
+
+	responseSize, err := readVarIntFrom(reader)
+	if err != nil {
+		return StatusResponse{}, shortResponseError(err, ErrShortStatusResponse)
+	}
+	if responseSize < 0 {
+		return StatusResponse{}, ErrInvalidSizeInfo
+	}
+
+	response := make([]byte, responseSize)
+	_, err = io.ReadFull(reader, response)
+	if err != nil {
+		return StatusResponse{}, shortResponseError(err, ErrShortStatusResponse)
+	}
+
+	return packageStatusResponse("", 0, -1, response, DefaultPlayerSampleLimit)
+}
+
+// ParseStatusLegacyResponse parses a legacy status response captured from a server.
+//
+// raw must contain the response exactly as sent by the server.
+// https://wiki.vg/Server_List_Ping#Server_to_client
+func ParseStatusLegacyResponse(raw []byte) (StatusLegacyResponse, error) {
+	return packageLegacyStatusResponse("", 0, -1, raw)
+}
+
+// ParseStatusBetaResponse parses a beta status response captured from a server.
+//
+// raw must contain the response exactly as sent by the server, starting with the kick packet ID.
+// https://wiki.vg/Server_List_Ping#Beta_1.8_to_1.3
+func ParseStatusBetaResponse(raw []byte) (StatusBetaResponse, error) {
+	if len(raw) < 3 {
+		return StatusBetaResponse{}, ErrStatusBetaMissingInformation
+	}
+
+	// The short after the kick packet ID contains the number of UTF-16 characters in the response.
+	responseSize := int(binary.BigEndian.Uint16(raw[1:3])) * 2
+	response := raw[3:]
+	if len(response) < responseSize {
+		return StatusBetaResponse{}, ErrStatusBetaMissingInformation
+	}
+
+	return packageBetaStatusResponse("", 0, -1, response[:responseSize])
+}
+
+// ParseBasicQueryResponse parses a basic query response captured from a server.
+//
+// raw must contain the datagram exactly as sent by the server.
+// https://wiki.vg/Query#Response_2
+func ParseBasicQueryResponse(raw []byte) (BasicQueryResponse, error) {
+	return packageBasicQueryResponse("", 0, -1, raw)
+}
+
+// ParseFullQueryResponse parses a full query response captured from a server.
+//
+// raw must contain the datagram exactly as sent by the server.
+// https://wiki.vg/Query#Response_3
+func ParseFullQueryResponse(raw []byte) (FullQueryResponse, error) {
+	return packageFullQueryResponse("", 0, -1, raw, defaultQueryRequiredKeys)
+}
+
+// shortResponseError replaces the errors caused by raw ending too early with shortErr.
+func shortResponseError(err error, shortErr error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return shortErr
+	}
+
+	return err
+}
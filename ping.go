@@ -0,0 +1,159 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Errors.
+var (
+	// ErrInvalidPingCount is returned when the requested number of pings is less than 1.
+	ErrInvalidPingCount error = errors.New("invalid ping count: at least one ping must be sent")
+)
+
+// PingStatistics contains the latency statistics from multiple pings.
+type PingStatistics struct {
+	// Samples contains the latency of each ping that received a pong, in the order they were sent.
+	Samples []time.Duration
+
+	// Sent contains the number of pings sent.
+	Sent int
+
+	// Lost contains the number of pings that timed out.
+	Lost int
+
+	// Loss contains the percentage of pings that timed out.
+	Loss float64
+
+	// Min contains the lowest latency.
+	Min time.Duration
+
+	// Max contains the highest latency.
+	Max time.Duration
+
+	// Avg contains the mean latency.
+	Avg time.Duration
+
+	// StdDev contains the population standard deviation of the latency.
+	StdDev time.Duration
+}
+
+// PingStats pings a Minecraft server count times in a row and calculates the latency statistics.
+//
+// The server closes the connection after each pong, so every ping is sent over a new connection.
+// Pings that time out are counted as lost rather than returned as an error.
+// Any other error stops the pings and is returned along with the statistics of the pings sent before it.
+//
+// If every ping is lost, only Sent, Lost, and Loss are set.
+// https://wiki.vg/Server_List_Ping#Ping
+func PingStats(server string, port uint16, count int, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (PingStatistics, error) {
+	_, pingStats, err := PingN(server, port, count, 0, initialConnectionTimeout, ioTimeout, opts...)
+
+	return pingStats, err
+}
+
+// PingN pings a Minecraft server count times, waiting interval between each ping, and returns the latency samples along with their statistics.
+//
+// Pings are sent the same way as PingStats, so StdDev of the statistics measures the jitter of the connection.
+// The context given with WithContext is checked between pings, and if it is done, the pings stop and its error is returned.
+// Whenever the pings stop early, the samples gathered so far and their statistics are returned along with the error.
+// https://wiki.vg/Server_List_Ping#Ping
+func PingN(server string, port uint16, count int, interval time.Duration, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) ([]time.Duration, PingStatistics, error) {
+	if count < 1 {
+		return nil, PingStatistics{}, ErrInvalidPingCount
+	}
+
+	ctx := newOptions(opts).context()
+	samples := []time.Duration{}
+	lost := 0
+
+	for i := 0; i < count; i++ {
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return samples, calculatePingStatistics(samples, lost), ctx.Err()
+		}
+
+		latency, err := Ping(server, port, initialConnectionTimeout, ioTimeout, opts...)
+		if err != nil {
+			// A DNS lookup timing out is returned rather than counted as lost, as the ping was never sent.
+			if errors.Is(err, ErrTimeout) {
+				lost++
+				continue
+			}
+
+			return samples, calculatePingStatistics(samples, lost), err
+		}
+
+		samples = append(samples, latency)
+	}
+
+	return samples, calculatePingStatistics(samples, lost), nil
+}
+
+// calculatePingStatistics packages the samples and the number of lost pings into PingStatistics.
+func calculatePingStatistics(samples []time.Duration, lost int) PingStatistics {
+	pingStats := PingStatistics{}
+	pingStats.Samples = samples
+	pingStats.Sent = len(samples) + lost
+	pingStats.Lost = lost
+	if pingStats.Sent > 0 {
+		pingStats.Loss = float64(lost) / float64(pingStats.Sent) * 100
+	}
+
+	if len(samples) == 0 {
+		return pingStats
+	}
+
+	pingStats.Min = samples[0]
+	pingStats.Max = samples[0]
+
+	var total time.Duration
+	for _, sample := range samples {
+		if sample < pingStats.Min {
+			pingStats.Min = sample
+		}
+		if sample > pingStats.Max {
+			pingStats.Max = sample
+		}
+		total += sample
+	}
+	pingStats.Avg = total / time.Duration(len(samples))
+
+	var variance float64
+	for _, sample := range samples {
+		difference := float64(sample - pingStats.Avg)
+		variance += difference * difference
+	}
+	variance /= float64(len(samples))
+	pingStats.StdDev = time.Duration(math.Sqrt(variance))
+
+	return pingStats
+}
+
+// IsOnline checks whether a Minecraft server is up by pinging it, returning true and the latency if it is.
+//
+// A server refusing the connection or timing out is considered down, in which case false is returned without an error.
+// Any other failure (such as a DNS failure or an invalid response) returns false along with the error.
+// A server that answers the status request but not the ping is up, in which case the latency is -1.
+// https://wiki.vg/Server_List_Ping#Ping
+func IsOnline(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (bool, time.Duration, error) {
+	status, err := Status(server, port, initialConnectionTimeout, ioTimeout, opts...)
+	if err == nil {
+		return true, status.Latency, nil
+	}
+
+	// The classified kinds are used rather than IsTimeout, which also matches a DNS lookup timing out.
+	if errors.Is(err, ErrConnectionRefused) || errors.Is(err, ErrTimeout) {
+		return false, -1, nil
+	}
+
+	return false, -1, err
+}
@@ -0,0 +1,109 @@
+package mcstatusgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPingNKeepsSamplesOnError(t *testing.T) {
+	var connections int32
+	answer := serveStatus(testDocument, 0)
+	port := startFakeServer(t, func(con net.Conn) {
+		// The third ping gets a response that isn't a status response.
+		if atomic.AddInt32(&connections, 1) == 3 {
+			con.Write([]byte{0x01, 0x07})
+			return
+		}
+		answer(con)
+	})
+
+	samples, pingStats, err := PingN("127.0.0.1", port, 5, 0, testTimeout, testTimeout)
+	if err == nil {
+		t.Fatal("PingN() succeeded with an invalid response")
+	}
+	if len(samples) != 2 {
+		t.Errorf("PingN() returned %d samples, want 2", len(samples))
+	}
+	if pingStats.Sent != 2 || pingStats.Lost != 0 || len(pingStats.Samples) != 2 {
+		t.Errorf("statistics = %d sent, %d lost, %d samples, want 2 sent, 0 lost, 2 samples", pingStats.Sent, pingStats.Lost, len(pingStats.Samples))
+	}
+}
+
+func TestPingNDNSFailure(t *testing.T) {
+	// The resolver never answers, so the lookup times out, which must be returned rather than counted as a lost ping.
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	samples, pingStats, err := PingN("mc.example.test", 25565, 3, 0, 100*time.Millisecond, testTimeout, WithResolver(resolver))
+	if !errors.Is(err, ErrDNS) {
+		t.Errorf("PingN() error = %v, want an error matching ErrDNS", err)
+	}
+	if len(samples) != 0 || pingStats.Sent != 0 || pingStats.Lost != 0 {
+		t.Errorf("PingN() = %d samples, %d sent, %d lost, want nothing sent", len(samples), pingStats.Sent, pingStats.Lost)
+	}
+}
+
+func TestCalculatePingStatistics(t *testing.T) {
+	samples := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	pingStats := calculatePingStatistics(samples, 1)
+
+	if pingStats.Sent != 4 || pingStats.Lost != 1 || pingStats.Loss != 25 {
+		t.Errorf("sent/lost/loss = %d/%d/%v, want 4/1/25", pingStats.Sent, pingStats.Lost, pingStats.Loss)
+	}
+	if pingStats.Min != 10*time.Millisecond || pingStats.Max != 30*time.Millisecond || pingStats.Avg != 20*time.Millisecond {
+		t.Errorf("min/avg/max = %v/%v/%v, want 10ms/20ms/30ms", pingStats.Min, pingStats.Avg, pingStats.Max)
+	}
+
+	// Nothing was sent before stopping, so the loss is zero rather than NaN.
+	if empty := calculatePingStatistics([]time.Duration{}, 0); empty.Loss != 0 {
+		t.Errorf("calculatePingStatistics() with nothing sent: Loss = %v, want 0", empty.Loss)
+	}
+}
+
+func TestIsOnline(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+	online, latency, err := IsOnline("127.0.0.1", port, testTimeout, testTimeout)
+	if !online || latency < 0 || err != nil {
+		t.Errorf("IsOnline() = %v, %v, %v, want true with a latency", online, latency, err)
+	}
+}
+
+func TestIsOnlineRefused(t *testing.T) {
+	// Nothing listens on the port once the listener is closed.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	online, latency, err := IsOnline("127.0.0.1", port, testTimeout, testTimeout)
+	if online || latency != -1 || err != nil {
+		t.Errorf("IsOnline() = %v, %v, %v, want a clean down", online, latency, err)
+	}
+}
+
+func TestIsOnlineDNSFailure(t *testing.T) {
+	// The resolver never answers, so the lookup times out, which must still be reported as a DNS failure.
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	online, _, err := IsOnline("mc.example.test", 25565, 100*time.Millisecond, testTimeout, WithResolver(resolver))
+	if online || !errors.Is(err, ErrDNS) {
+		t.Errorf("IsOnline() = %v, %v, want false with an error matching ErrDNS", online, err)
+	}
+}
@@ -0,0 +1,214 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+)
+
+// Errors.
+var (
+	// ErrExpectedJSONObject is returned when the status response contains a value that isn't a JSON object where one is expected.
+	ErrExpectedJSONObject error = errors.New("invalid status response: expected a JSON object")
+)
+
+// PlayerCountResponse contains the player counts and version retrieved from the status protocol by PlayerCount.
+type PlayerCountResponse struct {
+	// IP contains the server's IP.
+	IP string
+
+	// Port contains the server's port used for communication.
+	Port uint16
+
+	// Latency contains the duration of time waited for the pong from the server, which is the time to the first byte of the response if the ping was skipped, and -1 if the ping failed.
+	Latency time.Duration
+
+	// PingErr contains the error that caused the ping to fail, which doesn't fail the request.
+	//
+	// It is excluded from JSON like the PingErr of StatusResponse.
+	PingErr error `json:"-"`
+
+	Version struct {
+		// Name contains the version of Minecraft running on the server.
+		Name string
+	}
+
+	Players struct {
+		// Max contains the maximum number of players the server supports.
+		Max int
+
+		// Online contains the current number of players on the server.
+		Online int
+	}
+}
+
+// PlayerCount requests only the player counts and version name from a Minecraft server using the status protocol.
+//
+// The request is the same as Status, but the JSON response is streamed and every other field is skipped without being unmarshalled,
+// which saves CPU when polling many servers with large descriptions, favicons, or mod lists.
+//
+// If a valid response is received, a PlayerCountResponse is returned.
+// https://wiki.vg/Server_List_Ping
+func PlayerCount(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (PlayerCountResponse, error) {
+	options, cancel := newOptions(opts).withOverallTimeout()
+	defer cancel()
+
+	con, err := dial("tcp", server, port, initialConnectionTimeout, ProtocolStatus, options)
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+	defer resetConnection(con)
+
+	con, stats := options.wrapConn(con, ProtocolStatus)
+	startTime := time.Now()
+
+	playerCount, err := performPlayerCountRequest(con, server, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolStatus, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+
+	return playerCount, err
+}
+
+// performPlayerCountRequest performs the status request over con, only packaging the player counts and version name.
+func performPlayerCountRequest(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (PlayerCountResponse, error) {
+	serverIP := remoteIP(con)
+
+	exchange, err := requestStatus(con, server, port, ioTimeout, options)
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+
+	playerCount, err := packagePlayerCountResponse(serverIP, port, exchange.latency, exchange.response)
+	options.traceOutcome("player count", err)
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+	playerCount.PingErr = exchange.pingErr
+
+	err = options.validatePlayerCounts(playerCount.Players.Online, playerCount.Players.Max)
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+
+	return playerCount, nil
+}
+
+// packagePlayerCountResponse streams the player counts and version name out of the response into playerCount.
+func packagePlayerCountResponse(serverIP string, port uint16, latency time.Duration, response []byte) (PlayerCountResponse, error) {
+	playerCount := PlayerCountResponse{}
+	playerCount.IP = serverIP
+	playerCount.Port = port
+	playerCount.Latency = latency
+
+	formatedResponse, err := formatStatusResponse(response)
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+
+	var max, online *flexibleInt
+	var versionName *string
+
+	decoder := json.NewDecoder(bytes.NewReader(formatedResponse))
+	err = decodeJSONObject(decoder, func(key string) error {
+		switch key {
+		case "players":
+			return decodeJSONObject(decoder, func(key string) error {
+				switch key {
+				case "max":
+					max = new(flexibleInt)
+					return decoder.Decode(max)
+				case "online":
+					online = new(flexibleInt)
+					return decoder.Decode(online)
+				}
+
+				return skipJSONValue(decoder)
+			})
+		case "version":
+			return decodeJSONObject(decoder, func(key string) error {
+				if key == "name" {
+					versionName = new(string)
+					return decoder.Decode(versionName)
+				}
+
+				return skipJSONValue(decoder)
+			})
+		}
+
+		return skipJSONValue(decoder)
+	})
+	if err != nil {
+		return PlayerCountResponse{}, err
+	}
+
+	// Check if any of the values were left out from the status response.
+	if max == nil {
+		return PlayerCountResponse{}, ErrMissingInformation{"status", "max players"}
+	}
+	if online == nil {
+		return PlayerCountResponse{}, ErrMissingInformation{"status", "online players"}
+	}
+	if versionName == nil {
+		return PlayerCountResponse{}, ErrMissingInformation{"status", "version name"}
+	}
+
+	playerCount.Players.Max = int(*max)
+	playerCount.Players.Online = int(*online)
+	playerCount.Version.Name = *versionName
+
+	return playerCount, nil
+}
+
+// decodeJSONObject reads a JSON object from decoder, calling decodeValue with each key so it can decode or skip the value that follows.
+func decodeJSONObject(decoder *json.Decoder, decodeValue func(key string) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return ErrExpectedJSONObject
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		err = decodeValue(token.(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read the closing brace.
+	_, err = decoder.Token()
+
+	return err
+}
+
+// skipJSONValue reads past the next JSON value in decoder, including any nested objects and arrays.
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
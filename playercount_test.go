@@ -0,0 +1,62 @@
+package mcstatusgo
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPackagePlayerCountResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{"in order", testDocument},
+		{"reordered keys", `{"description":"A Minecraft Server","players":{"online":3,"sample":[{"name":"Steve","id":"069a79f4-44e9-4726-a5be-fca90e38aaf5"}],"max":20},"version":{"protocol":765,"name":"1.20.4"},"favicon":"data:image/png;base64,"}`},
+		{"string counts", `{"version":{"name":"1.20.4","protocol":765},"players":{"max":"20","online":"3"},"description":{"text":"A Minecraft Server","extra":[{"text":"!"}]}}`},
+	}
+
+	for _, test := range tests {
+		playerCount, err := packagePlayerCountResponse("", 0, -1, statusResponsePacket(test.document))
+		if err != nil {
+			t.Errorf("%s: packagePlayerCountResponse: %v", test.name, err)
+			continue
+		}
+		if playerCount.Players.Online != 3 || playerCount.Players.Max != 20 || playerCount.Version.Name != "1.20.4" {
+			t.Errorf("%s: response = %d/%d players on %q, want 3/20 on %q", test.name, playerCount.Players.Online, playerCount.Players.Max, playerCount.Version.Name, "1.20.4")
+		}
+	}
+}
+
+func TestPackagePlayerCountResponseMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     error
+	}{
+		{"missing players", `{"version":{"name":"1.20.4","protocol":765},"description":"A Minecraft Server"}`, ErrMissingInformation{"status", "max players"}},
+		{"missing online players", `{"version":{"name":"1.20.4","protocol":765},"players":{"max":20}}`, ErrMissingInformation{"status", "online players"}},
+		{"missing version", `{"players":{"max":20,"online":3}}`, ErrMissingInformation{"status", "version name"}},
+		{"players not an object", `{"version":{"name":"1.20.4"},"players":[20,3]}`, ErrExpectedJSONObject},
+	}
+
+	for _, test := range tests {
+		_, err := packagePlayerCountResponse("", 0, -1, statusResponsePacket(test.document))
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: packagePlayerCountResponse() error = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestPlayerCountResponseJSON(t *testing.T) {
+	playerCount := PlayerCountResponse{PingErr: errors.New("ping failed")}
+
+	encoded, err := json.Marshal(playerCount)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if strings.Contains(string(encoded), "PingErr") {
+		t.Errorf("json.Marshal() = %s, want PingErr excluded", encoded)
+	}
+}
@@ -0,0 +1,34 @@
+package mcstatusgo
+
+import "time"
+
+const (
+	// DefaultJavaPort is the default port of Java Edition servers, used by both the status and query protocols unless the server configures otherwise.
+	DefaultJavaPort uint16 = 25565
+	// DefaultBedrockPort is the default port of Bedrock Edition servers.
+	DefaultBedrockPort uint16 = 19132
+)
+
+// StatusDefault requests basic server information from a Minecraft server listening on DefaultJavaPort.
+// https://wiki.vg/Server_List_Ping
+func StatusDefault(server string, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (StatusResponse, error) {
+	return Status(server, DefaultJavaPort, initialConnectionTimeout, ioTimeout, opts...)
+}
+
+// PingDefault retrieves the latency of a Minecraft server listening on DefaultJavaPort.
+// https://wiki.vg/Server_List_Ping#Ping
+func PingDefault(server string, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (time.Duration, error) {
+	return Ping(server, DefaultJavaPort, initialConnectionTimeout, ioTimeout, opts...)
+}
+
+// BasicQueryDefault requests basic server information from a Minecraft server with its query port left at DefaultJavaPort.
+// https://wiki.vg/Query#Basic_stat
+func BasicQueryDefault(server string, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (BasicQueryResponse, error) {
+	return BasicQuery(server, DefaultJavaPort, initialConnectionTimeout, ioTimeout, opts...)
+}
+
+// FullQueryDefault requests detailed server information from a Minecraft server with its query port left at DefaultJavaPort.
+// https://wiki.vg/Query#Full_stat
+func FullQueryDefault(server string, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (FullQueryResponse, error) {
+	return FullQuery(server, DefaultJavaPort, initialConnectionTimeout, ioTimeout, opts...)
+}
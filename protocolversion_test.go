@@ -0,0 +1,26 @@
+package mcstatusgo
+
+import "testing"
+
+func TestProtocolFromVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"1.20.4", 765},
+		{"1.8", 47},
+		{"Paper 1.20.4", 765},
+		// The proxy's own version is skipped as it isn't a known release.
+		{"Velocity 3.1.2 1.20.4", 765},
+		{"Spigot 1.8.8-R0.1-SNAPSHOT", 47},
+		{"Unknown Server", -1},
+		{"1.99.9", -1},
+		{"", -1},
+	}
+
+	for _, test := range tests {
+		if got := ProtocolFromVersion(test.name); got != test.want {
+			t.Errorf("ProtocolFromVersion(%q) = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ProxyProtocolVersion identifies the version of the HAProxy PROXY protocol header sent by WithProxyProtocol.
+// https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt
+type ProxyProtocolVersion int
+
+const (
+	// ProxyProtocolV1 sends the human-readable PROXY protocol header.
+	ProxyProtocolV1 ProxyProtocolVersion = 1
+	// ProxyProtocolV2 sends the binary PROXY protocol header.
+	ProxyProtocolV2 ProxyProtocolVersion = 2
+)
+
+const (
+	// proxyV2VersionProxy is the version and command byte of a v2 header relaying a connection.
+	proxyV2VersionProxy byte = 0x21
+	// proxyV2VersionLocal is the version and command byte of a v2 header that doesn't relay a connection, used when the addresses can't be sent.
+	proxyV2VersionLocal byte = 0x20
+	// proxyV2TCP4 is the family and transport byte of a v2 header for TCP over IPv4.
+	proxyV2TCP4 byte = 0x11
+	// proxyV2TCP6 is the family and transport byte of a v2 header for TCP over IPv6.
+	proxyV2TCP6 byte = 0x21
+	// proxyV2Unspec is the family and transport byte of a v2 header without addresses.
+	proxyV2Unspec byte = 0x00
+)
+
+var (
+	// proxyV2Signature begins every v2 header.
+	proxyV2Signature []byte = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+	// proxyV1Prefix begins every v1 header.
+	proxyV1Prefix []byte = []byte("PROXY ")
+)
+
+// writeProxyHeader sends the PROXY protocol header over con if one was requested with WithProxyProtocol.
+func (o options) writeProxyHeader(con net.Conn, timeout time.Duration) error {
+	if o.proxyProtocol == 0 {
+		return nil
+	}
+
+	source := o.proxySource
+	if source == nil {
+		source = con.LocalAddr()
+	}
+	destination := o.proxyDestination
+	if destination == nil {
+		destination = con.RemoteAddr()
+	}
+
+	header, err := createProxyHeader(o.proxyProtocol, source, destination)
+	if err != nil {
+		return err
+	}
+	o.trace("proxy protocol header: % x", header)
+
+	return initiateRequest(con, timeout, header)
+}
+
+// isProxyHeader reports whether packet is a PROXY protocol header of either version.
+func isProxyHeader(packet []byte) bool {
+	return bytes.HasPrefix(packet, proxyV1Prefix) || bytes.HasPrefix(packet, proxyV2Signature)
+}
+
+// createProxyHeader crafts the PROXY protocol header of version relaying a connection from source to destination.
+//
+// If source and destination aren't TCP addresses of the same IP version, a header without addresses is crafted.
+func createProxyHeader(version ProxyProtocolVersion, source net.Addr, destination net.Addr) ([]byte, error) {
+	switch version {
+	case ProxyProtocolV1:
+		return createProxyV1Header(source, destination), nil
+	case ProxyProtocolV2:
+		return createProxyV2Header(source, destination), nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported PROXY protocol version %d", ErrInvalidArgument, version)
+}
+
+// createProxyV1Header crafts the human-readable v1 header.
+func createProxyV1Header(source net.Addr, destination net.Addr) []byte {
+	sourceAddr, destinationAddr, isIPv4, ok := proxyTCPAddrs(source, destination)
+	if !ok {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	family := "TCP6"
+	if isIPv4 {
+		family = "TCP4"
+	}
+
+	return []byte("PROXY " + family + " " + sourceAddr.IP.String() + " " + destinationAddr.IP.String() + " " +
+		strconv.Itoa(sourceAddr.Port) + " " + strconv.Itoa(destinationAddr.Port) + "\r\n")
+}
+
+// createProxyV2Header crafts the binary v2 header.
+func createProxyV2Header(source net.Addr, destination net.Addr) []byte {
+	header := []byte(proxyV2Signature)
+
+	sourceAddr, destinationAddr, isIPv4, ok := proxyTCPAddrs(source, destination)
+	if !ok {
+		return append(header, proxyV2VersionLocal, proxyV2Unspec, 0x00, 0x00)
+	}
+
+	var addresses []byte
+	family := proxyV2TCP6
+	if isIPv4 {
+		family = proxyV2TCP4
+		addresses = append(addresses, sourceAddr.IP.To4()...)
+		addresses = append(addresses, destinationAddr.IP.To4()...)
+	} else {
+		addresses = append(addresses, sourceAddr.IP.To16()...)
+		addresses = append(addresses, destinationAddr.IP.To16()...)
+	}
+	addresses = append(addresses, portToBytes(uint16(sourceAddr.Port))...)
+	addresses = append(addresses, portToBytes(uint16(destinationAddr.Port))...)
+
+	addressesLength := make([]byte, 2)
+	binary.BigEndian.PutUint16(addressesLength, uint16(len(addresses)))
+
+	header = append(header, proxyV2VersionProxy, family)
+	header = append(header, addressesLength...)
+
+	return append(header, addresses...)
+}
+
+// proxyTCPAddrs returns source and destination as TCP addresses and whether they are IPv4, if they are TCP addresses of the same IP version.
+func proxyTCPAddrs(source net.Addr, destination net.Addr) (*net.TCPAddr, *net.TCPAddr, bool, bool) {
+	sourceAddr, ok := source.(*net.TCPAddr)
+	if !ok || sourceAddr.IP == nil {
+		return nil, nil, false, false
+	}
+	destinationAddr, ok := destination.(*net.TCPAddr)
+	if !ok || destinationAddr.IP == nil {
+		return nil, nil, false, false
+	}
+
+	sourceIsIPv4 := sourceAddr.IP.To4() != nil
+	if sourceIsIPv4 != (destinationAddr.IP.To4() != nil) {
+		return nil, nil, false, false
+	}
+
+	return sourceAddr, destinationAddr, sourceIsIPv4, true
+}
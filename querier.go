@@ -0,0 +1,67 @@
+package mcstatusgo
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Querier holds an open UDP connection to a Minecraft server along with its challenge token, so basic and full query requests can share one handshake.
+//
+// The challenge token is cached for DefaultChallengeTokenTTL, or by the cache given with WithChallengeTokenCache, and the handshake is only performed again once it expires or the server rejects it.
+// Requests are serialized over the connection. A Querier is safe for concurrent use.
+// https://wiki.vg/Query#Handshake
+type Querier struct {
+	con       net.Conn
+	port      uint16
+	ioTimeout time.Duration
+	options   options
+
+	mutex sync.Mutex
+}
+
+// NewQuerier dials the server and creates a Querier using the given io timeout and options for every request.
+//
+// The Minecraft server must have the "enable-query" property set to true.
+// The connection is kept open until Close is called.
+func NewQuerier(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (*Querier, error) {
+	options := newOptions(opts)
+	if options.tokenCache == nil {
+		options.tokenCache = NewChallengeTokenCache(DefaultChallengeTokenTTL)
+	}
+
+	con, err := dial("udp", server, port, initialConnectionTimeout, ProtocolBasicQuery, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Querier{
+		con:       con,
+		port:      port,
+		ioTimeout: ioTimeout,
+		options:   options,
+	}, nil
+}
+
+// Basic requests basic server information, reusing the challenge token of a previous request if it is still valid.
+// https://wiki.vg/Query#Basic_stat
+func (q *Querier) Basic() (BasicQueryResponse, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return basicQueryConn(q.con, q.port, q.ioTimeout, q.options)
+}
+
+// Full requests detailed server information, reusing the challenge token of a previous request if it is still valid.
+// https://wiki.vg/Query#Full_stat
+func (q *Querier) Full() (FullQueryResponse, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return fullQueryConn(q.con, q.port, q.ioTimeout, q.options)
+}
+
+// Close closes the connection to the server. Requests made after Close return an error.
+func (q *Querier) Close() error {
+	return q.con.Close()
+}
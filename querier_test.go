@@ -0,0 +1,76 @@
+package mcstatusgo
+
+import (
+	"net"
+	"testing"
+)
+
+func TestQuerier(t *testing.T) {
+	server := startFakeQueryServer(t)
+	port := uint16(server.con.LocalAddr().(*net.UDPAddr).Port)
+
+	querier, err := NewQuerier("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+	if err != nil {
+		t.Fatalf("NewQuerier: %v", err)
+	}
+	defer querier.Close()
+
+	basicQuery, err := querier.Basic()
+	if err != nil {
+		t.Fatalf("Basic: %v", err)
+	}
+	if basicQuery.Players.Online != 3 || basicQuery.Players.Max != 20 {
+		t.Errorf("basic query players = %d/%d, want 3/20", basicQuery.Players.Online, basicQuery.Players.Max)
+	}
+
+	fullQuery, err := querier.Full()
+	if err != nil {
+		t.Fatalf("Full: %v", err)
+	}
+	if len(fullQuery.Players.PlayerList) != 2 || !fullQuery.Complete {
+		t.Errorf("full query players = %v (complete %v), want [Steve Alex]", fullQuery.Players.PlayerList, fullQuery.Complete)
+	}
+
+	// Both requests share the challenge token of a single handshake.
+	if handshakes := server.handshakeCount(); handshakes != 1 {
+		t.Errorf("handshakes = %d, want 1", handshakes)
+	}
+}
+
+func TestQuerierAndClientAgree(t *testing.T) {
+	server := startFakeQueryServer(t)
+	port := uint16(server.con.LocalAddr().(*net.UDPAddr).Port)
+
+	querier, err := NewQuerier("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+	if err != nil {
+		t.Fatalf("NewQuerier: %v", err)
+	}
+	defer querier.Close()
+	client := NewClient("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+
+	// Querier and Client both answer basic and full queries for the same server.
+	implementations := []struct {
+		name  string
+		basic func() (BasicQueryResponse, error)
+		full  func() (FullQueryResponse, error)
+	}{
+		{"Querier", querier.Basic, querier.Full},
+		{"Client", client.BasicQuery, client.FullQuery},
+	}
+
+	for _, implementation := range implementations {
+		basicQuery, err := implementation.basic()
+		if err != nil {
+			t.Errorf("%s: basic query: %v", implementation.name, err)
+		} else if basicQuery.MapName != "world" || basicQuery.Port != port {
+			t.Errorf("%s: basic query map %q on port %d, want %q on %d", implementation.name, basicQuery.MapName, basicQuery.Port, "world", port)
+		}
+
+		fullQuery, err := implementation.full()
+		if err != nil {
+			t.Errorf("%s: full query: %v", implementation.name, err)
+		} else if fullQuery.Version.Name != "1.20.4" || fullQuery.Port != port {
+			t.Errorf("%s: full query version %q on port %d, want %q on %d", implementation.name, fullQuery.Version.Name, fullQuery.Port, "1.20.4", port)
+		}
+	}
+}
@@ -5,20 +5,26 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"math/rand"
 	"net"
-	"reflect"
 	"strconv"
 	"strings"
 	"time"
 )
 
 const (
+	// sessionIDMask is applied to every session ID, as the server only echoes the lower 4 bits of each byte.
+	sessionIDMask uint32 = 0x0F0F0F0F
 	// handshakeByte identifies the crafted packet as a handshake packet.
 	handshakeByte byte = 0x09
 	// statByte identifies the packet as a request for query information.
 	statByte byte = 0x00
+	// challengeTokenBufferSize is the size of the buffer the challenge token response is read into.
+	challengeTokenBufferSize int = 512
+	// queryRequestPacketSize is the size of the full query request packet, the largest packet sent by the query protocols.
+	queryRequestPacketSize int = 15
+	// maxQueryDatagramSize is the largest UDP payload, used as the read buffer size when a full query response fills the read buffer.
+	maxQueryDatagramSize int = 65507
 )
 
 var (
@@ -28,6 +34,9 @@ var (
 	fullQueryPadding []byte = []byte{0x00, 0x00, 0x00, 0x00}
 	// playerSplit is the token used to split the full query response into two parts for parsing.
 	playerToken []byte = []byte{0x00, 0x01, 0x70, 0x6C, 0x61, 0x79, 0x65, 0x72, 0x5F, 0x00, 0x00}
+	// defaultQueryRequiredKeys are the keys a full query response must contain unless WithQueryRequiredKeys is given.
+	// game_id and plugins are optional, as some non-vanilla servers leave them out.
+	defaultQueryRequiredKeys []string = []string{"hostname", "gametype", "version", "map", "numplayers", "maxplayers"}
 )
 
 // Errors.
@@ -36,10 +45,18 @@ var (
 	ErrShortQueryResponse error = errors.New("invalid query response: response is too small to contain valid data")
 	// ErrShortChallengeToken is returned when the received challenge token is too small to be valid.
 	ErrShortChallengeToken error = errors.New("invalid query response: challenge token is too small to contain valid data")
-	// ErrAbsentChallengeTokenNullTerminator is returned when the challenge token doesn't contain a null-terminator at the end.
+	// ErrAbsentChallengeTokenNullTerminator is returned when the challenge token doesn't contain a null-terminator.
 	ErrAbsentChallengeTokenNullTerminator = errors.New("invalid query response: challenge token doesn't contain a null-terminator")
 	// ErrAbsentPlayerToken is returned when the player token used to split the full query response into two parts for parsing isn't present.
 	ErrAbsentPlayerToken error = errors.New("invalid query response: player token not in response")
+	// ErrQueryUnavailable is matched by errors.Is when the server doesn't answer the query handshake, usually because "enable-query" is false or the query port is wrong.
+	//
+	// The error returned is still a NetworkError of Kind ErrTimeout or ErrConnectionRefused, so errors.Is matches that Kind as well.
+	ErrQueryUnavailable error = errors.New("query unavailable: server didn't answer the query handshake")
+	// ErrSessionIDMismatch is returned when the session ID echoed by the server doesn't match the one sent, meaning the response belongs to a different exchange.
+	ErrSessionIDMismatch error = errors.New("invalid query response: echoed session ID doesn't match the one sent")
+	// ErrTruncatedQueryResponse is passed to the OnRetry hook when the full query response filled the read buffer and is requested again with a buffer of the largest datagram size.
+	ErrTruncatedQueryResponse error = errors.New("invalid query response: response filled the read buffer and may be truncated")
 )
 
 // BasicQueryResponse contains the information from the basic query request.
@@ -51,6 +68,20 @@ type BasicQueryResponse struct {
 	// Port contains the server's port used for communication.
 	Port uint16
 
+	// RemoteAddr contains the address the connection actually landed on, including the port.
+	//
+	// It is excluded from JSON like the RemoteAddr of StatusResponse.
+	RemoteAddr net.Addr `json:"-"`
+
+	// SessionID contains the session ID echoed by the server, which matches the one sent in the request.
+	SessionID uint32
+
+	// BytesSent contains the total number of bytes sent to the server during the request, including the challenge handshake.
+	BytesSent int `json:"-"`
+
+	// BytesReceived contains the total number of bytes received from the server during the request, including the challenge handshake.
+	BytesReceived int `json:"-"`
+
 	// Latency contains the duration of time waited for the basic query response.
 	Latency time.Duration
 
@@ -60,6 +91,9 @@ type BasicQueryResponse struct {
 	// Gametype contains a string which is usually 'SMP'.
 	GameType string
 
+	// GameMode contains GameType normalized, which is GameModeUnknown if the server reported an unexpected game type.
+	GameMode GameMode
+
 	// MapName contains the name of the map running on the server.
 	MapName string
 
@@ -78,32 +112,67 @@ type BasicQueryResponse struct {
 //
 // If a valid response is received, a BasicQueryResponse is returned.
 // https://wiki.vg/Query#Basic_stat
-func BasicQuery(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (BasicQueryResponse, error) {
-	serverAndPort := fmt.Sprintf("%s:%d", server, port)
+func BasicQuery(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (BasicQueryResponse, error) {
+	return basicQueryDial(server, port, initialConnectionTimeout, ioTimeout, newOptions(opts))
+}
 
-	con, err := net.DialTimeout("udp", serverAndPort, initialConnectionTimeout)
+// basicQueryDial dials the server and performs the basic query.
+func basicQueryDial(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (BasicQueryResponse, error) {
+	con, err := dial("udp", server, port, initialConnectionTimeout, ProtocolBasicQuery, options)
 	if err != nil {
 		return BasicQueryResponse{}, err
 	}
-	// If the connection closes normally, this line will run but not do anything.
 	defer con.Close()
 
-	// Split the string "IP:PORT" by : to get the IP of the remote host.
-	serverIP := strings.Split(con.RemoteAddr().String(), ":")[0]
+	return basicQueryConn(con, port, ioTimeout, options)
+}
+
+// BasicQueryConn requests basic server information from a Minecraft server over an already established connection.
+//
+// port is only used for the response, as the connection is neither dialed nor closed.
+// The lifecycle of con is left to the caller.
+//
+// If a valid response is received, a BasicQueryResponse is returned.
+// https://wiki.vg/Query#Basic_stat
+func BasicQueryConn(con net.Conn, port uint16, ioTimeout time.Duration, opts ...Option) (BasicQueryResponse, error) {
+	return basicQueryConn(con, port, ioTimeout, newOptions(opts))
+}
+
+// basicQueryConn performs the basic query request over con.
+func basicQueryConn(con net.Conn, port uint16, ioTimeout time.Duration, options options) (BasicQueryResponse, error) {
+	con, stats := options.wrapConn(con, ProtocolBasicQuery)
+	startTime := time.Now()
+
+	basicQuery, err := performBasicQueryRequest(con, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolBasicQuery, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+	if err == nil {
+		basicQuery.BytesSent = stats.BytesSent
+		basicQuery.BytesReceived = stats.BytesReceived
+	}
+
+	return basicQuery, err
+}
+
+// performBasicQueryRequest performs the basic query request over con.
+func performBasicQueryRequest(con net.Conn, port uint16, ioTimeout time.Duration, options options) (BasicQueryResponse, error) {
+	serverIP := remoteIP(con)
 
-	err = initiateQueryRequest(con, ioTimeout, false)
+	response, latency, err := requestQuery(con, ioTimeout, false, options)
 	if err != nil {
 		return BasicQueryResponse{}, err
 	}
+	options.trace("basic query response: %d bytes", len(response))
 
-	response, latency, err := readQueryResponse(con, ioTimeout)
+	basicQuery, err := packageBasicQueryResponse(serverIP, port, latency, response)
+	options.traceOutcome("basic query", err)
 	if err != nil {
 		return BasicQueryResponse{}, err
 	}
+	basicQuery.RemoteAddr = con.RemoteAddr()
 
-	con.Close()
-
-	basicQuery, err := packageBasicQueryResponse(serverIP, port, latency, response)
+	err = options.validatePlayerCounts(basicQuery.Players.Online, basicQuery.Players.Max)
 	if err != nil {
 		return BasicQueryResponse{}, err
 	}
@@ -120,6 +189,20 @@ type FullQueryResponse struct {
 	// Port contains the server's port used for communication.
 	Port uint16
 
+	// RemoteAddr contains the address the connection actually landed on, including the port.
+	//
+	// It is excluded from JSON like the RemoteAddr of StatusResponse.
+	RemoteAddr net.Addr `json:"-"`
+
+	// SessionID contains the session ID echoed by the server, which matches the one sent in the request.
+	SessionID uint32
+
+	// BytesSent contains the total number of bytes sent to the server during the request, including the challenge handshake.
+	BytesSent int `json:"-"`
+
+	// BytesReceived contains the total number of bytes received from the server during the request, including the challenge handshake.
+	BytesReceived int `json:"-"`
+
 	// Latency contains the duration of time waited for the full query response.
 	Latency time.Duration
 
@@ -129,6 +212,9 @@ type FullQueryResponse struct {
 	// Gametype contains a string which is usually 'SMP'.
 	GameType string
 
+	// GameMode contains GameType normalized, which is GameModeUnknown if the server reported an unexpected game type.
+	GameMode GameMode
+
 	// GameID contains a string which is usually 'MINECRAFT'.
 	GameID string
 
@@ -149,15 +235,60 @@ type FullQueryResponse struct {
 
 		// PlayerList contains the usernames of the players currently on the server.
 		PlayerList []string
+
+		// PlayerListTruncated contains whether the player section was cut off before its terminating null, in which case PlayerList is incomplete.
+		PlayerListTruncated bool
 	}
 
+	// Complete contains whether the whole response was received, which requires the player section to be present and terminated.
+	//
+	// A response that fills the read buffer is requested again with a buffer of the largest datagram size,
+	// so Complete is only false if the response was still cut off, such as by a larger response than a datagram can hold.
+	Complete bool
+
 	ModInfo struct {
 		// Type contains the server mod running on the server.
 		Type string
 
 		// ModList contains the plugins with their versions running on the server.
-		ModList []map[string]string
+		ModList []Mod
 	}
+
+	// Whitelist contains whether the server reported its whitelist as enabled using the non-standard "whitelist" key, which is false if the key is absent.
+	Whitelist bool
+
+	// ServerSoftware contains the server software reported using the non-standard "software" key, which is empty if the key is absent.
+	ServerSoftware string
+
+	// RawKV contains every key and value of the K,V section as sent by the server, including server-specific keys that aren't modeled by the other fields.
+	RawKV map[string]string
+}
+
+// ToStatus converts f into a StatusResponse so responses from either protocol can be handled as one type.
+//
+// IP, Port, RemoteAddr, BytesSent, BytesReceived, Latency, Version.Name, Players.Max, Players.Online, and ModInfo are copied as is.
+// Description is converted into the pretty-print chat component JSON used by StatusResponse.
+// The fields only sent by the status protocol are left zeroed: Favicon, Version.Protocol, the player sample, ModInfo.NetworkVersion, and the chat flags.
+// The query only fields, such as GameType, MapName, and PlayerList, have no equivalent and are dropped.
+func (f FullQueryResponse) ToStatus() StatusResponse {
+	status := StatusResponse{}
+	status.IP = f.IP
+	status.Port = f.Port
+	status.RemoteAddr = f.RemoteAddr
+	status.BytesSent = f.BytesSent
+	status.BytesReceived = f.BytesReceived
+	status.Latency = f.Latency
+	status.Version.Name = f.Version.Name
+	status.Players.Max = f.Players.Max
+	status.Players.Online = f.Players.Online
+	status.ModInfo.Type = f.ModInfo.Type
+	status.ModInfo.ModList = f.ModInfo.ModList
+
+	// Marshalling a map of strings can't fail.
+	descJSONBytes, _ := json.MarshalIndent(normalizeDescription(f.Description), "", "  ")
+	status.Description = string(descJSONBytes)
+
+	return status
 }
 
 // FullQuery requests detailed server information from a Minecraft server.
@@ -166,32 +297,67 @@ type FullQueryResponse struct {
 //
 // If a valid response is received, a FullQueryResponse is returned.
 // https://wiki.vg/Query#Full_stat
-func FullQuery(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (FullQueryResponse, error) {
-	serverAndPort := fmt.Sprintf("%s:%d", server, port)
+func FullQuery(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (FullQueryResponse, error) {
+	return fullQueryDial(server, port, initialConnectionTimeout, ioTimeout, newOptions(opts))
+}
 
-	con, err := net.DialTimeout("udp", serverAndPort, initialConnectionTimeout)
+// fullQueryDial dials the server and performs the full query.
+func fullQueryDial(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (FullQueryResponse, error) {
+	con, err := dial("udp", server, port, initialConnectionTimeout, ProtocolFullQuery, options)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
-	// If the connection closes normally, this line will run but not do anything.
 	defer con.Close()
 
-	// Split the string "IP:PORT" by : to get the IP of the remote host.
-	serverIP := strings.Split(con.RemoteAddr().String(), ":")[0]
+	return fullQueryConn(con, port, ioTimeout, options)
+}
 
-	err = initiateQueryRequest(con, ioTimeout, true)
+// FullQueryConn requests detailed server information from a Minecraft server over an already established connection.
+//
+// port is only used for the response, as the connection is neither dialed nor closed.
+// The lifecycle of con is left to the caller.
+//
+// If a valid response is received, a FullQueryResponse is returned.
+// https://wiki.vg/Query#Full_stat
+func FullQueryConn(con net.Conn, port uint16, ioTimeout time.Duration, opts ...Option) (FullQueryResponse, error) {
+	return fullQueryConn(con, port, ioTimeout, newOptions(opts))
+}
+
+// fullQueryConn performs the full query request over con.
+func fullQueryConn(con net.Conn, port uint16, ioTimeout time.Duration, options options) (FullQueryResponse, error) {
+	con, stats := options.wrapConn(con, ProtocolFullQuery)
+	startTime := time.Now()
+
+	fullQuery, err := performFullQueryRequest(con, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolFullQuery, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+	if err == nil {
+		fullQuery.BytesSent = stats.BytesSent
+		fullQuery.BytesReceived = stats.BytesReceived
+	}
+
+	return fullQuery, err
+}
+
+// performFullQueryRequest performs the full query request over con.
+func performFullQueryRequest(con net.Conn, port uint16, ioTimeout time.Duration, options options) (FullQueryResponse, error) {
+	serverIP := remoteIP(con)
+
+	response, latency, err := requestQuery(con, ioTimeout, true, options)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
+	options.trace("full query response: %d bytes", len(response))
 
-	response, latency, err := readQueryResponse(con, ioTimeout)
+	fullQuery, err := packageFullQueryResponse(serverIP, port, latency, response, options.queryRequiredKeys())
+	options.traceOutcome("full query", err)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
+	fullQuery.RemoteAddr = con.RemoteAddr()
 
-	con.Close()
-
-	fullQuery, err := packageFullQueryResponse(serverIP, port, latency, response)
+	err = options.validatePlayerCounts(fullQuery.Players.Online, fullQuery.Players.Max)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
@@ -199,61 +365,192 @@ func FullQuery(server string, port uint16, initialConnectionTimeout time.Duratio
 	return fullQuery, nil
 }
 
+// requestQuery sends the query request and receives the response.
+//
+// If a cached challenge token was used and the server didn't respond, the token is discarded and the request is retried once with a new handshake.
+func requestQuery(con net.Conn, timeout time.Duration, isFullQuery bool, options options) ([]byte, time.Duration, error) {
+	request, err := initiateQueryRequest(con, timeout, isFullQuery, options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	bufferSize := options.readBufferSizeOr(queryReadBufferSize)
+	response, latency, err := readQueryResponse(con, options.queryResponseTimeout(timeout), bufferSize, request)
+
+	// Servers silently ignore requests containing an expired challenge token.
+	if err != nil && request.usedCachedToken && IsTimeout(err) {
+		options.trace("query cached challenge token rejected")
+		options.hookRetry(queryProtocol(isFullQuery), err)
+		options.tokenCache.invalidate(con)
+
+		request, err = initiateQueryRequest(con, timeout, isFullQuery, options)
+		if err != nil {
+			return nil, -1, err
+		}
+
+		response, latency, err = readQueryResponse(con, options.queryResponseTimeout(timeout), bufferSize, request)
+	}
+
+	// A datagram larger than the read buffer is silently cut off, so a response filling the buffer is requested again with room for any datagram.
+	if err == nil && isFullQuery && isDatagramConn(con) && len(response) == bufferSize && bufferSize < maxQueryDatagramSize {
+		options.trace("full query response filled the %d byte read buffer", bufferSize)
+		options.hookRetry(ProtocolFullQuery, ErrTruncatedQueryResponse)
+
+		request, err = initiateQueryRequest(con, timeout, isFullQuery, options)
+		if err != nil {
+			return nil, -1, err
+		}
+
+		response, latency, err = readQueryResponse(con, options.queryResponseTimeout(timeout), maxQueryDatagramSize, request)
+	}
+
+	return response, latency, err
+}
+
+// queryProtocol returns the StatusProtocol identifying the basic or full query protocol.
+func queryProtocol(isFullQuery bool) StatusProtocol {
+	if isFullQuery {
+		return ProtocolFullQuery
+	}
+
+	return ProtocolBasicQuery
+}
+
+// queryRequest contains the state of a sent query request needed to receive its response.
+type queryRequest struct {
+	// sessionID is the session ID sent in the request, which the server echoes in its response.
+	sessionID []byte
+	// timer is started when the request packet is sent, so the latency doesn't include the handshake.
+	timer latencyTimer
+	// usedCachedToken is set if the handshake was skipped because a cached challenge token was available.
+	usedCachedToken bool
+}
+
 // initiateQueryRequest handles sending the handshake and request packets.
-func initiateQueryRequest(con net.Conn, timeout time.Duration, isFullQuery bool) error {
-	sessionID := createSessionID()
-	handshake := createQueryHandshakePacket(sessionID)
+//
+// The handshake is skipped if a cached challenge token is available.
+func initiateQueryRequest(con net.Conn, timeout time.Duration, isFullQuery bool, options options) (queryRequest, error) {
+	sessionID, challengeToken, usedCachedToken := options.tokenCache.get(con)
 
-	challengeToken, err := readChallengeToken(con, timeout, handshake)
-	if err != nil {
-		return err
+	// The buffer fits the request packet, so it is reused for the handshake and building either packet doesn't reallocate.
+	packet := make([]byte, 0, queryRequestPacketSize)
+
+	if !usedCachedToken {
+		sessionID = options.querySessionID()
+		packet = appendQueryHandshakePacket(packet[:0], sessionID)
+
+		var err error
+		challengeToken, err = readChallengeToken(con, options.queryHandshakeTimeout(timeout), packet, sessionID)
+		if err != nil {
+			return queryRequest{}, err
+		}
+		options.trace("query challenge token: % x", challengeToken)
+
+		options.tokenCache.put(con, sessionID, challengeToken)
 	}
 
-	queryRequestPacket := createQueryRequestPacket(sessionID, challengeToken, isFullQuery)
-	err = initiateRequest(con, timeout, queryRequestPacket)
+	packet = appendQueryRequestPacket(packet[:0], sessionID, challengeToken, isFullQuery)
+	timer := options.startLatencyTimer()
+	err := initiateRequest(con, options.queryResponseTimeout(timeout), packet)
 
-	return err
+	return queryRequest{sessionID, timer, usedCachedToken}, err
 }
 
 // createSessionID creates a random sessionID for the query request.
 // https://wiki.vg/Query#Generating_a_Session_ID
 func createSessionID() []byte {
 	rand.Seed(time.Now().UnixNano())
-	sessionID := make([]byte, 4)
 
-	randomSessionID := 0x0F0F0F0F & rand.Int()
-	binary.BigEndian.PutUint32(sessionID, uint32(randomSessionID))
+	return sessionIDToBytes(uint32(rand.Int()))
+}
+
+// sessionIDToBytes masks sessionID as the server expects and converts it into its []byte equivalent.
+// https://wiki.vg/Query#Generating_a_Session_ID
+func sessionIDToBytes(sessionID uint32) []byte {
+	sessionIDBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(sessionIDBytes, sessionID&sessionIDMask)
 
-	return sessionID
+	return sessionIDBytes
 }
 
 // createQueryHandshakePacket crafts the handshake packet used to initiate the request.
 // https://wiki.vg/Query#Handshake
 func createQueryHandshakePacket(sessionID []byte) []byte {
-	handshake := []byte(magicBytes)
-	handshake = append(handshake, handshakeByte)
-	handshake = append(handshake, sessionID...)
+	return appendQueryHandshakePacket(make([]byte, 0, len(magicBytes)+1+len(sessionID)), sessionID)
+}
+
+// appendQueryHandshakePacket appends the handshake packet to dst and returns the extended slice, allocating nothing if dst has enough capacity.
+// https://wiki.vg/Query#Handshake
+func appendQueryHandshakePacket(dst []byte, sessionID []byte) []byte {
+	dst = append(dst, magicBytes...)
+	dst = append(dst, handshakeByte)
+
+	return append(dst, sessionID...)
+}
+
+// queryUnavailableError marks the NetworkError caused by the server not answering the query handshake as ErrQueryUnavailable without changing its message.
+type queryUnavailableError struct {
+	err NetworkError
+}
+
+func (e queryUnavailableError) Error() string {
+	return e.err.Error()
+}
 
-	return handshake
+// Unwrap returns the NetworkError of the timeout or refused connection.
+func (e queryUnavailableError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is ErrQueryUnavailable.
+func (e queryUnavailableError) Is(target error) bool {
+	return target == ErrQueryUnavailable
 }
 
 // readChallengeToken reads and parses the challenge token sent by the server.
-func readChallengeToken(con net.Conn, timeout time.Duration, handshake []byte) ([]byte, error) {
+//
+// An error is returned if the response doesn't echo sessionID, and ErrQueryUnavailable is returned if the server doesn't answer.
+func readChallengeToken(con net.Conn, timeout time.Duration, handshake []byte, sessionID []byte) ([]byte, error) {
 	setDeadline(&con, timeout)
 	_, err := con.Write(handshake)
 	if err != nil {
 		return nil, err
 	}
 
-	potentialChallengeToken := make([]byte, 32)
+	// The buffer is much larger than a valid challenge token response so that padded responses aren't truncated.
+	potentialChallengeToken := make([]byte, challengeTokenBufferSize)
 	setDeadline(&con, timeout)
 
 	bytesRead, err := con.Read(potentialChallengeToken)
 	if err != nil {
+		// A server with query disabled silently drops the handshake, or refuses it if nothing listens on the port.
+		if IsConnectionRefused(err) {
+			return nil, queryUnavailableError{NetworkError{ErrConnectionRefused, err}}
+		}
+		if IsTimeout(err) {
+			return nil, queryUnavailableError{NetworkError{ErrTimeout, err}}
+		}
+
 		return nil, err
 	}
+
+	// Datagrams are received whole, but a stream connection given to the Conn functions can split the response across reads.
+	if !isDatagramConn(con) {
+		for bytesRead < len(potentialChallengeToken) && !hasChallengeTokenTerminator(potentialChallengeToken[:bytesRead]) {
+			n, err := con.Read(potentialChallengeToken[bytesRead:])
+			if err != nil {
+				return nil, err
+			}
+			bytesRead += n
+		}
+	}
 	potentialChallengeToken = potentialChallengeToken[0:bytesRead]
 
+	err = checkSessionID(potentialChallengeToken, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
 	challengeToken, err := parseChallengeToken(potentialChallengeToken)
 	if err != nil {
 		return nil, err
@@ -262,6 +559,22 @@ func readChallengeToken(con net.Conn, timeout time.Duration, handshake []byte) (
 	return challengeToken, nil
 }
 
+// isDatagramConn reports whether con receives whole datagrams, as UDP connections do.
+func isDatagramConn(con net.Conn) bool {
+	localAddr := con.LocalAddr()
+
+	return localAddr != nil && strings.HasPrefix(localAddr.Network(), "udp")
+}
+
+// hasChallengeTokenTerminator reports whether the challenge token response received so far contains the null-terminator of the token.
+func hasChallengeTokenTerminator(potentialChallengeToken []byte) bool {
+	if len(potentialChallengeToken) < 5 {
+		return false
+	}
+
+	return bytes.IndexByte(bytes.TrimLeft(potentialChallengeToken[5:], "\x00"), 0) != -1
+}
+
 // parseChallengeToken parses the cleaned challenge token into an int contained in a []byte.
 func parseChallengeToken(potentialChallengeToken []byte) ([]byte, error) {
 	challengeTokenString, err := cleanChallengeToken(potentialChallengeToken)
@@ -269,24 +582,12 @@ func parseChallengeToken(potentialChallengeToken []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	var isNegativeChallengeToken bool
-
-	// If challenge token is negative, remove the negative sign from the front and set bool.
-	if challengeTokenString[0] == '-' {
-		challengeTokenString = challengeTokenString[1:]
-		isNegativeChallengeToken = true
-	}
-
+	// The challenge token can be negative, which stringToInt handles along with the full int32 range.
 	challengeTokenInt, err := stringToInt(challengeTokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	// Make challenge token negative.
-	if isNegativeChallengeToken {
-		challengeTokenInt *= -1
-	}
-
 	challengeTokenBytes := make([]byte, 4)
 	binary.BigEndian.PutUint32(challengeTokenBytes, uint32(challengeTokenInt))
 
@@ -294,63 +595,94 @@ func parseChallengeToken(potentialChallengeToken []byte) ([]byte, error) {
 }
 
 // cleanChallengeToken checks and formats the received challenge token.
+//
+// The challenge token ends at the first null-terminator, so any padding or extra data after it is ignored.
 func cleanChallengeToken(potentialChallengeToken []byte) (string, error) {
 	if len(potentialChallengeToken) < 7 {
 		return "", ErrShortChallengeToken
 	}
 
-	// Remove Type and sessionID bytes from the beginning.
-	potentialChallengeToken = potentialChallengeToken[5:]
+	// Remove Type and sessionID bytes and any padding nulls from the beginning.
+	potentialChallengeToken = bytes.TrimLeft(potentialChallengeToken[5:], "\x00")
 
-	// Return an error if the challenge token doesn't have a null-terminator at the end.
-	if potentialChallengeToken[len(potentialChallengeToken)-1] != 0 {
-		return "", ErrAbsentChallengeTokenNullTerminator
+	// A response of only padding contains no token at all.
+	if len(potentialChallengeToken) == 0 {
+		return "", ErrShortChallengeToken
 	}
 
-	// Remove any lingering null-terminators.
-	cleanedToken := []byte{}
-	for _, currentByte := range potentialChallengeToken {
-		if currentByte != 0 {
-			cleanedToken = append(cleanedToken, currentByte)
-		}
+	// Return an error if the challenge token doesn't have a null-terminator.
+	nullTerminatorIndex := bytes.IndexByte(potentialChallengeToken, 0)
+	if nullTerminatorIndex == -1 {
+		return "", ErrAbsentChallengeTokenNullTerminator
 	}
 
-	return string(cleanedToken), nil
+	return string(potentialChallengeToken[:nullTerminatorIndex]), nil
 }
 
 // createQueryRequestPacket uses the information received from the handshake to create the full query request packet.
 // https://wiki.vg/Query#Request_2 (basic query).
 // https://wiki.vg/Query#Request_3 (full query).
 func createQueryRequestPacket(sessionID []byte, challengeToken []byte, isFullQuery bool) []byte {
-	fullQueryRequestPacket := append(magicBytes, statByte)
-	fullQueryRequestPacket = append(fullQueryRequestPacket, sessionID...)
-	fullQueryRequestPacket = append(fullQueryRequestPacket, challengeToken...)
+	size := len(magicBytes) + 1 + len(sessionID) + len(challengeToken) + len(fullQueryPadding)
+
+	return appendQueryRequestPacket(make([]byte, 0, size), sessionID, challengeToken, isFullQuery)
+}
+
+// appendQueryRequestPacket appends the request packet to dst and returns the extended slice, allocating nothing if dst has enough capacity.
+// https://wiki.vg/Query#Request_2 (basic query).
+// https://wiki.vg/Query#Request_3 (full query).
+func appendQueryRequestPacket(dst []byte, sessionID []byte, challengeToken []byte, isFullQuery bool) []byte {
+	dst = append(dst, magicBytes...)
+	dst = append(dst, statByte)
+	dst = append(dst, sessionID...)
+	dst = append(dst, challengeToken...)
 
 	// If full query, add the extra padding.
 	if isFullQuery {
-		fullQueryRequestPacket = append(fullQueryRequestPacket, fullQueryPadding...)
+		dst = append(dst, fullQueryPadding...)
 	}
 
-	return fullQueryRequestPacket
+	return dst
 }
 
-// readQueryResponse receives and measures the duration of time waited for the query response.
-func readQueryResponse(con net.Conn, timeout time.Duration) ([]byte, time.Duration, error) {
-	response := make([]byte, 8192)
+// readQueryResponse receives the response to request and measures the duration of time waited for it.
+//
+// An error is returned if the response doesn't echo the session ID of request.
+func readQueryResponse(con net.Conn, timeout time.Duration, bufferSize int, request queryRequest) ([]byte, time.Duration, error) {
+	response := make([]byte, bufferSize)
 	setDeadline(&con, timeout)
 
-	startTime := time.Now()
 	bytesRead, err := con.Read(response)
 	if err != nil {
 		return nil, -1, err
 	}
-	latency := time.Since(startTime)
+	latency := request.timer.elapsed()
 
 	response = response[0:bytesRead]
 
+	err = checkSessionID(response, request.sessionID)
+	if err != nil {
+		return nil, -1, err
+	}
+
 	return response, latency, nil
 }
 
+// checkSessionID checks that the session ID echoed after the type byte of response matches sessionID.
+//
+// Responses too short to contain a session ID are left for the parsers to reject.
+func checkSessionID(response []byte, sessionID []byte) error {
+	if len(response) < 5 {
+		return nil
+	}
+
+	if !bytes.Equal(response[1:5], sessionID) {
+		return ErrSessionIDMismatch
+	}
+
+	return nil
+}
+
 // packageBasicQueryResponse parses and packages the response into basicQuery.
 func packageBasicQueryResponse(serverIP string, port uint16, latency time.Duration, response []byte) (BasicQueryResponse, error) {
 	basicQuery := BasicQueryResponse{}
@@ -372,6 +704,8 @@ func parseBasicQueryResponse(response []byte, basicQuery *BasicQueryResponse) er
 		return ErrShortQueryResponse
 	}
 
+	basicQuery.SessionID = binary.BigEndian.Uint32(response[1:5])
+
 	// Remove type and sessionID bytes from the front.
 	response = response[5:]
 
@@ -396,6 +730,7 @@ func parseBasicQueryResponse(response []byte, basicQuery *BasicQueryResponse) er
 	// Package first three string values.
 	basicQuery.Description = string(responseSlice[0])
 	basicQuery.GameType = string(responseSlice[1])
+	basicQuery.GameMode = NormalizeGameMode(basicQuery.GameType)
 	basicQuery.MapName = string(responseSlice[2])
 
 	// Convert and package the int values.
@@ -425,44 +760,63 @@ func stringToInt(numString string) (int, error) {
 }
 
 // packageFullQueryResponse parses and packages the response into fullQuery.
-func packageFullQueryResponse(serverIP string, port uint16, latency time.Duration, response []byte) (FullQueryResponse, error) {
+//
+// An error is only returned for a missing key if it is one of requiredKeys.
+func packageFullQueryResponse(serverIP string, port uint16, latency time.Duration, response []byte, requiredKeys []string) (FullQueryResponse, error) {
 	fullQuery := FullQueryResponse{}
 	fullQuery.IP = serverIP
 	fullQuery.Port = port
 	fullQuery.Latency = latency
 
-	// Split the response using the player token into a key value section and a null-terminated string section containing the players online for parsing.
-	splitResponse := bytes.Split(response, playerToken)
-	if len(splitResponse) != 2 {
-		return FullQueryResponse{}, ErrAbsentPlayerToken
+	keyValueSection, playerSection, err := splitFullQueryResponse(response)
+	if err != nil {
+		return FullQueryResponse{}, err
 	}
 
-	keyValueSection := splitResponse[0]
-	playerSection := splitResponse[1]
-
-	responseMapBytes, err := parseKeyValueSection(keyValueSection)
+	responseMap, err := parseKeyValueSection(keyValueSection)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
+	fullQuery.RawKV = responseMap
+	fullQuery.SessionID = binary.BigEndian.Uint32(keyValueSection[1:5])
 
-	err = validateQueryResponse(responseMapBytes)
+	err = validateQueryResponse(responseMap, requiredKeys)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
 
-	err = packageKeyValueSection(responseMapBytes, &fullQuery)
+	err = packageKeyValueSection(responseMap, &fullQuery)
 	if err != nil {
 		return FullQueryResponse{}, err
 	}
 
 	packagePlayerSection(playerSection, &fullQuery)
+	// A K,V section cut off right after a null pair can't be told apart from one terminated by an empty key, so only the player section proves the end of the response arrived.
+	fullQuery.Complete = playerSection != nil && !fullQuery.Players.PlayerListTruncated
 
 	return fullQuery, nil
 }
 
-// parseKeyValueSection parses the key mapped values from the full query response into a JSON []byte.
+// splitFullQueryResponse splits the response using the player token into a key value section and a null-terminated string section containing the players online for parsing.
+//
+// Only the first occurrence of the player token is split on, so plugin strings containing the token's bytes don't break parsing.
+// If the player token is absent but the key value section ends with a null pair, which may be its terminating empty key, the player section is nil.
+func splitFullQueryResponse(response []byte) ([]byte, []byte, error) {
+	playerTokenIndex := bytes.Index(response, playerToken)
+	if playerTokenIndex != -1 {
+		return response[:playerTokenIndex], response[playerTokenIndex+len(playerToken):], nil
+	}
+
+	if bytes.HasSuffix(response, []byte{0x00, 0x00}) {
+		return response, nil, nil
+	}
+
+	return nil, nil, ErrAbsentPlayerToken
+}
+
+// parseKeyValueSection parses the key mapped values from the full query response into a map.
 // https://wiki.vg/Query#K.2C_V_section
-func parseKeyValueSection(keyValueSection []byte) ([]byte, error) {
+func parseKeyValueSection(keyValueSection []byte) (map[string]string, error) {
 	if len(keyValueSection) < 16 {
 		return nil, ErrShortQueryResponse
 	}
@@ -497,63 +851,59 @@ func parseKeyValueSection(keyValueSection []byte) ([]byte, error) {
 		}
 	}
 
-	responseMapBytes, err := json.Marshal(responseMap)
-	if err != nil {
-		return nil, err
-	}
-
-	return responseMapBytes, nil
+	return responseMap, nil
 }
 
-// validateQueryResponse checks for missing information from the query response.
-func validateQueryResponse(responseMapBytes []byte) error {
-	var verifyResponse struct {
-		Hostname, Gametype, Game_id, Version, Plugins, Map, Numplayers, Maxplayers interface{}
-	}
-
-	err := json.Unmarshal(responseMapBytes, &verifyResponse)
-	if err != nil {
-		return err
-	}
-
-	values := reflect.ValueOf(verifyResponse)
-	for i := 0; i < values.NumField(); i++ {
-		valueType := values.Field(i).Interface()
-		valueName := strings.ToLower(values.Type().Field(i).Name)
-
-		// A value was left out from query response.
-		if valueType == nil {
-			return ErrMissingInformation{"query", valueName}
-		}
-	}
-
-	return nil
+// validateQueryResponse checks that each of requiredKeys is present in the query response.
+func validateQueryResponse(responseMap map[string]string, requiredKeys []string) error {
+	return checkRequiredFields("query", requiredKeys, func(key string) bool {
+		_, ok := responseMap[key]
+		return ok
+	})
 }
 
 // packageKeyValueSection manually unmarshals and packages the key value section into fullQuery to preserve an identitical structure to StatusResponse{}.
-func packageKeyValueSection(responseMapBytes []byte, fullQuery *FullQueryResponse) error {
+func packageKeyValueSection(responseMap map[string]string, fullQuery *FullQueryResponse) error {
 	var keyValueInfo struct {
-		Maxplayers, Numplayers                             int `json:",string"`
+		Maxplayers, Numplayers                             flexibleInt
 		Hostname, Gametype, Game_id, Map, Version, Plugins string
 	}
 
-	err := json.Unmarshal(responseMapBytes, &keyValueInfo)
+	responseMapBytes, err := json.Marshal(responseMap)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(responseMapBytes, &keyValueInfo)
 	if err != nil {
 		return err
 	}
 
-	fullQuery.Players.Max = keyValueInfo.Maxplayers
-	fullQuery.Players.Online = keyValueInfo.Numplayers
+	fullQuery.Players.Max = int(keyValueInfo.Maxplayers)
+	fullQuery.Players.Online = int(keyValueInfo.Numplayers)
 	fullQuery.Description = keyValueInfo.Hostname
 	fullQuery.GameType = keyValueInfo.Gametype
+	fullQuery.GameMode = NormalizeGameMode(fullQuery.GameType)
 	fullQuery.GameID = keyValueInfo.Game_id
 	fullQuery.MapName = keyValueInfo.Map
 	fullQuery.Version.Name = keyValueInfo.Version
+	fullQuery.Whitelist = parseQueryBool(responseMap["whitelist"])
+	fullQuery.ServerSoftware = responseMap["software"]
 	packagePluginSection(keyValueInfo.Plugins, fullQuery)
 
 	return nil
 }
 
+// parseQueryBool parses the "on" and "true" values some servers send for boolean keys, treating anything else as false.
+func parseQueryBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "true", "yes", "1":
+		return true
+	}
+
+	return false
+}
+
 // packagePluginSection parses and packages the plugin section into fullQuery.
 func packagePluginSection(pluginSection string, fullQuery *FullQueryResponse) {
 	// The server is vanilla or doesn't send plugin information.
@@ -570,7 +920,7 @@ func packagePluginSection(pluginSection string, fullQuery *FullQueryResponse) {
 		return
 	}
 
-	pluginList := []map[string]string{}
+	pluginList := []Mod{}
 	pluginString := pluginSectionSplit[1]
 
 	if pluginString != "" {
@@ -591,9 +941,7 @@ func packagePluginSection(pluginSection string, fullQuery *FullQueryResponse) {
 				pluginVersion = pluginSplit[1]
 			}
 
-			completedPlugin := make(map[string]string)
-			completedPlugin[pluginName] = pluginVersion
-			pluginList = append(pluginList, completedPlugin)
+			pluginList = append(pluginList, Mod{Name: pluginName, Version: pluginVersion})
 		}
 	}
 	fullQuery.ModInfo.Type = serverModName
@@ -601,19 +949,24 @@ func packagePluginSection(pluginSection string, fullQuery *FullQueryResponse) {
 }
 
 // packagePlayerSection parses and packages the player section into fullQuery.
+//
+// If the response was cut off before the player section terminated, the players read so far are packaged and PlayerListTruncated is set.
+// A nil playerSection means the response had no player token, which isn't treated as truncated.
 func packagePlayerSection(playerSection []byte, fullQuery *FullQueryResponse) {
-	if len(playerSection) < 4 {
+	if playerSection == nil {
 		return
 	}
 
 	playerList := []string{}
 	playerString := []byte{}
+	terminated := false
 
 	for _, currentByte := range playerSection {
 		// playerString has terminated.
 		if currentByte == 0 {
 			// Player section has terminated.
 			if len(playerString) == 0 {
+				terminated = true
 				break
 			}
 
@@ -624,4 +977,5 @@ func packagePlayerSection(playerSection []byte, fullQuery *FullQueryResponse) {
 		}
 	}
 	fullQuery.Players.PlayerList = playerList
-}
\ No newline at end of file
+	fullQuery.Players.PlayerListTruncated = !terminated
+}
@@ -0,0 +1,436 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// queryTestTimeout is the io timeout used by requests to the fake query server, kept short as rejected tokens are only detected by timing out.
+	queryTestTimeout time.Duration = 200 * time.Millisecond
+)
+
+func TestParseChallengeToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  []byte
+	}{
+		{"positive", "9513307", []byte{0x00, 0x91, 0x29, 0x5B}},
+		{"max int32", "2147483647", []byte{0x7F, 0xFF, 0xFF, 0xFF}},
+		{"negative", "-1", []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{"min int32", "-2147483648", []byte{0x80, 0x00, 0x00, 0x00}},
+		{"padded", "\x00\x0012345", []byte{0x00, 0x00, 0x30, 0x39}},
+	}
+
+	for _, test := range tests {
+		response := append([]byte{handshakeByte, 0x01, 0x02, 0x03, 0x04}, test.token+"\x00"...)
+		got, err := parseChallengeToken(response)
+		if err != nil {
+			t.Errorf("%s: parseChallengeToken: %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: parseChallengeToken() = % x, want % x", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseChallengeTokenInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		response []byte
+		want     error
+	}{
+		{"short", []byte{handshakeByte, 0x01, 0x02, 0x03, 0x04, '1'}, ErrShortChallengeToken},
+		{"unterminated", []byte{handshakeByte, 0x01, 0x02, 0x03, 0x04, '1', '2'}, ErrAbsentChallengeTokenNullTerminator},
+		{"empty", []byte{handshakeByte, 0x01, 0x02, 0x03, 0x04, 0x00, 0x00}, ErrShortChallengeToken},
+		{"only padding", []byte{handshakeByte, 0x01, 0x02, 0x03, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, ErrShortChallengeToken},
+	}
+
+	for _, test := range tests {
+		_, err := parseChallengeToken(test.response)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: parseChallengeToken() error = %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	// Tokens outside the int32 range are rejected rather than wrapped.
+	for _, token := range []string{"2147483648", "-2147483649", "abc"} {
+		response := append([]byte{handshakeByte, 0x01, 0x02, 0x03, 0x04}, token+"\x00"...)
+		if _, err := parseChallengeToken(response); err == nil {
+			t.Errorf("parseChallengeToken(%q) succeeded, want an error", token)
+		}
+	}
+}
+
+func TestReadChallengeTokenSplit(t *testing.T) {
+	sessionID := []byte{0x01, 0x02, 0x03, 0x04}
+	response := append([]byte{handshakeByte}, sessionID...)
+	response = append(response, "-2147483648\x00"...)
+
+	tests := []struct {
+		name  string
+		split int
+	}{
+		{"after the header", 5},
+		{"in the middle of the token", 5 + len("-21474")},
+		{"before the null-terminator", len(response) - 1},
+	}
+
+	for _, test := range tests {
+		client, server := net.Pipe()
+
+		go func() {
+			defer server.Close()
+
+			// The handshake is read first, as writes to a pipe block until they are read.
+			handshake := make([]byte, 64)
+			if _, err := server.Read(handshake); err != nil {
+				return
+			}
+			server.Write(response[:test.split])
+			server.Write(response[test.split:])
+		}()
+
+		got, err := readChallengeToken(client, testTimeout, createQueryHandshakePacket(sessionID), sessionID)
+		client.Close()
+		if err != nil {
+			t.Errorf("%s: readChallengeToken: %v", test.name, err)
+			continue
+		}
+		if want := []byte{0x80, 0x00, 0x00, 0x00}; !bytes.Equal(got, want) {
+			t.Errorf("%s: readChallengeToken() = % x, want % x", test.name, got, want)
+		}
+	}
+}
+
+func BenchmarkQueryRequest(b *testing.B) {
+	sessionID := []byte{0x01, 0x02, 0x03, 0x04}
+	challengeToken := []byte{0x00, 0x9B, 0x4A, 0x7C}
+
+	b.Run("Create", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkPacket = createQueryRequestPacket(sessionID, challengeToken, true)
+		}
+	})
+
+	b.Run("Append", func(b *testing.B) {
+		b.ReportAllocs()
+		packet := createQueryRequestPacket(sessionID, challengeToken, true)
+		for i := 0; i < b.N; i++ {
+			packet = appendQueryRequestPacket(packet[:0], sessionID, challengeToken, true)
+		}
+		benchmarkPacket = packet
+	})
+}
+
+// fakeQueryServer answers query handshakes and basic and full query requests over UDP, only accepting the latest challenge token it issued.
+type fakeQueryServer struct {
+	con net.PacketConn
+
+	mutex      sync.Mutex
+	token      int32
+	handshakes int
+	// wrongSessionID makes stat responses echo a session ID other than the one sent, as if they belonged to another exchange.
+	wrongSessionID bool
+}
+
+// startFakeQueryServer listens on a local UDP port and answers query requests until the test ends.
+func startFakeQueryServer(t *testing.T) *fakeQueryServer {
+	t.Helper()
+
+	con, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { con.Close() })
+
+	server := &fakeQueryServer{con: con, token: 9513307}
+	go server.serve()
+
+	return server
+}
+
+func (s *fakeQueryServer) serve() {
+	packet := make([]byte, 1500)
+	for {
+		bytesRead, addr, err := s.con.ReadFrom(packet)
+		if err != nil {
+			return
+		}
+		if bytesRead < 7 || !bytes.HasPrefix(packet, magicBytes) {
+			continue
+		}
+		sessionID := packet[3:7]
+
+		s.mutex.Lock()
+		switch packet[2] {
+		case handshakeByte:
+			s.handshakes++
+			response := append([]byte{handshakeByte}, sessionID...)
+			s.con.WriteTo(append(response, strconv.Itoa(int(s.token))+"\x00"...), addr)
+		case statByte:
+			// Requests with a token other than the latest are silently ignored, as real servers do.
+			if bytesRead >= 11 && int32(binary.BigEndian.Uint32(packet[7:11])) == s.token {
+				response := append([]byte{statByte}, sessionID...)
+				if s.wrongSessionID {
+					response[4] ^= 0x01
+				}
+				// The full query request is padded to 15 bytes.
+				if bytesRead == 15 {
+					response = append(response, "splitnum\x00\x80\x00"+testFullQueryKeyValues+"\x01player_\x00\x00Steve\x00Alex\x00\x00"...)
+				} else {
+					response = append(response, "A Minecraft Server\x00SMP\x00world\x003\x0020\x00\xdd\x63127.0.0.1\x00"...)
+				}
+				s.con.WriteTo(response, addr)
+			}
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// rotateToken issues a new challenge token, invalidating the one cached by clients.
+func (s *fakeQueryServer) rotateToken() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token++
+}
+
+// handshakeCount returns the number of handshakes answered.
+func (s *fakeQueryServer) handshakeCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.handshakes
+}
+
+func TestChallengeTokenCache(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// prepare runs between the first and second request.
+		prepare        func(server *fakeQueryServer)
+		wantHandshakes int
+		wantRetries    int
+	}{
+		{"cached token", time.Minute, func(*fakeQueryServer) {}, 1, 0},
+		{"expired token", time.Millisecond, func(*fakeQueryServer) { time.Sleep(10 * time.Millisecond) }, 2, 0},
+		// The rejected token isn't answered, so the request times out before a new handshake is made.
+		{"rejected token", time.Minute, (*fakeQueryServer).rotateToken, 2, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := startFakeQueryServer(t)
+			con, err := net.Dial("udp", server.con.LocalAddr().String())
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer con.Close()
+
+			retries := 0
+			opts := []Option{
+				WithChallengeTokenCache(NewChallengeTokenCache(test.ttl)),
+				WithHooks(Hooks{OnRetry: func(StatusProtocol, error) { retries++ }}),
+			}
+
+			for i := 0; i < 2; i++ {
+				if i == 1 {
+					test.prepare(server)
+				}
+
+				basicQuery, err := BasicQueryConn(con, 25565, queryTestTimeout, opts...)
+				if err != nil {
+					t.Fatalf("request %d: BasicQueryConn: %v", i+1, err)
+				}
+				if basicQuery.Players.Online != 3 || basicQuery.Players.Max != 20 {
+					t.Errorf("request %d: players = %d/%d, want 3/20", i+1, basicQuery.Players.Online, basicQuery.Players.Max)
+				}
+			}
+
+			if handshakes := server.handshakeCount(); handshakes != test.wantHandshakes {
+				t.Errorf("handshakes = %d, want %d", handshakes, test.wantHandshakes)
+			}
+			if retries != test.wantRetries {
+				t.Errorf("retries = %d, want %d", retries, test.wantRetries)
+			}
+		})
+	}
+}
+
+func TestChallengeTokenCacheWithLocalAddr(t *testing.T) {
+	server := startFakeQueryServer(t)
+	port := uint16(server.con.LocalAddr().(*net.UDPAddr).Port)
+
+	// A free local port is found by listening on it, and is then bound by every dialed request.
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	localAddr := listener.LocalAddr()
+	listener.Close()
+
+	cache := NewChallengeTokenCache(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := BasicQuery("127.0.0.1", port, queryTestTimeout, queryTestTimeout, WithChallengeTokenCache(cache), WithLocalAddr(localAddr)); err != nil {
+			t.Fatalf("request %d: BasicQuery: %v", i+1, err)
+		}
+	}
+
+	if handshakes := server.handshakeCount(); handshakes != 1 {
+		t.Errorf("handshakes = %d, want 1", handshakes)
+	}
+}
+
+func TestChallengeTokenCacheWithoutCache(t *testing.T) {
+	server := startFakeQueryServer(t)
+	con, err := net.Dial("udp", server.con.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer con.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := BasicQueryConn(con, 25565, queryTestTimeout); err != nil {
+			t.Fatalf("BasicQueryConn: %v", err)
+		}
+	}
+
+	// Every request performs the handshake when no cache is given.
+	if handshakes := server.handshakeCount(); handshakes != 2 {
+		t.Errorf("handshakes = %d, want 2", handshakes)
+	}
+}
+
+func TestQueryUnavailable(t *testing.T) {
+	// The listener never answers, like a server with query disabled.
+	con, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer con.Close()
+	port := uint16(con.LocalAddr().(*net.UDPAddr).Port)
+
+	_, err = BasicQuery("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+	if !errors.Is(err, ErrQueryUnavailable) || !errors.Is(err, ErrTimeout) {
+		t.Fatalf("BasicQuery() = %v, want an error matching ErrQueryUnavailable and ErrTimeout", err)
+	}
+
+	var networkErr NetworkError
+	if !errors.As(err, &networkErr) || networkErr.Kind != ErrTimeout {
+		t.Errorf("errors.As(%v) didn't find a NetworkError of Kind ErrTimeout", err)
+	}
+	if err.Error() != networkErr.Error() || !strings.HasPrefix(err.Error(), ErrTimeout.Error()+": ") {
+		t.Errorf("BasicQuery() error = %q, want the message of the timeout's NetworkError", err)
+	}
+}
+
+func TestQuerySessionIDMismatch(t *testing.T) {
+	server := startFakeQueryServer(t)
+	server.mutex.Lock()
+	server.wrongSessionID = true
+	server.mutex.Unlock()
+	port := uint16(server.con.LocalAddr().(*net.UDPAddr).Port)
+
+	_, err := BasicQuery("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+	if !errors.Is(err, ErrSessionIDMismatch) {
+		t.Errorf("BasicQuery() error = %v, want ErrSessionIDMismatch", err)
+	}
+
+	_, err = FullQuery("127.0.0.1", port, queryTestTimeout, queryTestTimeout)
+	if !errors.Is(err, ErrSessionIDMismatch) {
+		t.Errorf("FullQuery() error = %v, want ErrSessionIDMismatch", err)
+	}
+}
+
+// testFullQueryKeyValues is the K,V section of a full query response after its header, terminated by an empty key.
+const testFullQueryKeyValues string = "hostname\x00A Minecraft Server\x00gametype\x00SMP\x00game_id\x00MINECRAFT\x00version\x001.20.4\x00" +
+	"map\x00world\x00numplayers\x002\x00maxplayers\x0020\x00plugins\x00\x00hostport\x0025565\x00hostip\x00127.0.0.1\x00\x00"
+
+func TestPackageFullQueryResponseComplete(t *testing.T) {
+	header := "\x00\x01\x02\x03\x04splitnum\x00\x80\x00"
+	full := header + testFullQueryKeyValues + "\x01player_\x00\x00Steve\x00Alex\x00\x00"
+
+	tests := []struct {
+		name          string
+		response      string
+		wantComplete  bool
+		wantTruncated bool
+	}{
+		{"complete", full, true, false},
+		{"cut off in the player section", full[:len(full)-6], false, true},
+		// The datagram ends at the null pair of the empty "plugins" value, like a K,V section terminated by an empty key.
+		{"cut off at a null pair", full[:strings.Index(full, "plugins\x00\x00")+len("plugins\x00\x00")], false, false},
+	}
+
+	for _, test := range tests {
+		fullQuery, err := ParseFullQueryResponse([]byte(test.response))
+		if err != nil {
+			t.Errorf("%s: ParseFullQueryResponse: %v", test.name, err)
+			continue
+		}
+		if fullQuery.Complete != test.wantComplete || fullQuery.Players.PlayerListTruncated != test.wantTruncated {
+			t.Errorf("%s: Complete = %v, PlayerListTruncated = %v, want %v, %v", test.name, fullQuery.Complete, fullQuery.Players.PlayerListTruncated, test.wantComplete, test.wantTruncated)
+		}
+	}
+}
+
+func TestFullQueryResponseToStatus(t *testing.T) {
+	fullQuery := FullQueryResponse{}
+	fullQuery.IP = "127.0.0.1"
+	fullQuery.Port = 25565
+	fullQuery.RemoteAddr = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 25565}
+	fullQuery.BytesSent = 26
+	fullQuery.BytesReceived = 240
+	fullQuery.Latency = 15 * time.Millisecond
+	fullQuery.Description = "A Minecraft Server"
+	fullQuery.GameType = "SMP"
+	fullQuery.MapName = "world"
+	fullQuery.Version.Name = "1.20.4"
+	fullQuery.Players.Max = 20
+	fullQuery.Players.Online = 2
+	fullQuery.Players.PlayerList = []string{"Steve", "Alex"}
+	fullQuery.ModInfo.Type = "Paper on 1.20.4"
+	fullQuery.ModInfo.ModList = []Mod{{Name: "WorldEdit", Version: "7.2.15"}}
+
+	status := fullQuery.ToStatus()
+
+	if status.IP != fullQuery.IP || status.Port != fullQuery.Port || status.RemoteAddr != fullQuery.RemoteAddr {
+		t.Errorf("address = %s:%d (%v), want %s:%d (%v)", status.IP, status.Port, status.RemoteAddr, fullQuery.IP, fullQuery.Port, fullQuery.RemoteAddr)
+	}
+	if status.BytesSent != 26 || status.BytesReceived != 240 || status.Latency != fullQuery.Latency {
+		t.Errorf("traffic = %d/%d bytes in %v, want 26/240 bytes in %v", status.BytesSent, status.BytesReceived, status.Latency, fullQuery.Latency)
+	}
+	if status.Version.Name != "1.20.4" || status.Players.Max != 20 || status.Players.Online != 2 {
+		t.Errorf("version %q with %d/%d players, want version 1.20.4 with 2/20 players", status.Version.Name, status.Players.Online, status.Players.Max)
+	}
+	if status.ModInfo.Type != fullQuery.ModInfo.Type || !reflect.DeepEqual(status.ModInfo.ModList, fullQuery.ModInfo.ModList) {
+		t.Errorf("ModInfo = %+v, want the type and mods of the full query", status.ModInfo)
+	}
+
+	// The description is converted into the chat component JSON used by the status protocol.
+	var description map[string]string
+	if err := json.Unmarshal([]byte(status.Description), &description); err != nil || description["text"] != "A Minecraft Server" {
+		t.Errorf("Description = %q, want a chat component with the MOTD as its text", status.Description)
+	}
+
+	// The fields only sent by the status protocol are left zeroed.
+	if status.Favicon != "" || status.Version.Protocol != 0 || status.Players.Sample != nil || status.Players.SampleTruncated || status.Players.SampleBanners != nil {
+		t.Errorf("status only fields = %q, %d, %v, %v, %v, want them zeroed", status.Favicon, status.Version.Protocol, status.Players.Sample, status.Players.SampleTruncated, status.Players.SampleBanners)
+	}
+	if status.ModInfo.NetworkVersion != "" || status.EnforcesSecureChat != nil || status.PreviewsChat != nil || status.PingErr != nil {
+		t.Errorf("NetworkVersion = %q, EnforcesSecureChat = %v, PreviewsChat = %v, PingErr = %v, want them zeroed", status.ModInfo.NetworkVersion, status.EnforcesSecureChat, status.PreviewsChat, status.PingErr)
+	}
+}
@@ -0,0 +1,106 @@
+package mcstatusgo
+
+import (
+	"time"
+)
+
+// ServerStatus contains the information shared by the responses of every implementation of the status protocol.
+//
+// It is implemented by StatusResponse, StatusLegacyResponse, StatusBetaResponse, and StatusAutoResponse, so monitoring code can handle any of them without a type switch.
+type ServerStatus interface {
+	// OnlinePlayers returns the current number of players on the server.
+	OnlinePlayers() int
+
+	// MaxPlayers returns the maximum number of players the server supports.
+	MaxPlayers() int
+
+	// MOTD returns the description of the server as plain text, without chat component formatting or legacy § formatting codes.
+	MOTD() string
+
+	// PingLatency returns the latency measured by the request, which is -1 if it wasn't measured.
+	PingLatency() time.Duration
+}
+
+// OnlinePlayers returns the current number of players on the server.
+func (s StatusResponse) OnlinePlayers() int {
+	return s.Players.Online
+}
+
+// MaxPlayers returns the maximum number of players the server supports.
+func (s StatusResponse) MaxPlayers() int {
+	return s.Players.Max
+}
+
+// MOTD returns the description of the server as plain text, using PlainDescription.
+func (s StatusResponse) MOTD() string {
+	return s.PlainDescription()
+}
+
+// PingLatency returns the duration of time waited for the pong, or for the response if the ping was skipped, which is -1 if the ping failed.
+func (s StatusResponse) PingLatency() time.Duration {
+	return s.Latency
+}
+
+// OnlinePlayers returns the current number of players on the server.
+func (s StatusLegacyResponse) OnlinePlayers() int {
+	return s.Players.Online
+}
+
+// MaxPlayers returns the maximum number of players the server supports.
+func (s StatusLegacyResponse) MaxPlayers() int {
+	return s.Players.Max
+}
+
+// MOTD returns the description of the server as plain text, with the § formatting codes removed.
+func (s StatusLegacyResponse) MOTD() string {
+	return stripLegacyCodes(s.Description)
+}
+
+// PingLatency returns the duration of time waited for the response.
+func (s StatusLegacyResponse) PingLatency() time.Duration {
+	return s.Latency
+}
+
+// OnlinePlayers returns the current number of players on the server.
+func (s StatusBetaResponse) OnlinePlayers() int {
+	return s.Players.Online
+}
+
+// MaxPlayers returns the maximum number of players the server supports.
+func (s StatusBetaResponse) MaxPlayers() int {
+	return s.Players.Max
+}
+
+// MOTD returns the description of the server as plain text, with the § formatting codes removed.
+func (s StatusBetaResponse) MOTD() string {
+	return stripLegacyCodes(s.Description)
+}
+
+// PingLatency returns the duration of time waited for the response.
+func (s StatusBetaResponse) PingLatency() time.Duration {
+	return s.Latency
+}
+
+// OnlinePlayers returns the current number of players on the server.
+func (s StatusAutoResponse) OnlinePlayers() int {
+	return s.Players.Online
+}
+
+// MaxPlayers returns the maximum number of players the server supports.
+func (s StatusAutoResponse) MaxPlayers() int {
+	return s.Players.Max
+}
+
+// MOTD returns the description of the server as plain text, whichever protocol answered.
+func (s StatusAutoResponse) MOTD() string {
+	if s.Status != nil {
+		return s.Status.MOTD()
+	}
+
+	return stripLegacyCodes(s.Description)
+}
+
+// PingLatency returns the duration of time waited for the response.
+func (s StatusAutoResponse) PingLatency() time.Duration {
+	return s.Latency
+}
@@ -0,0 +1,27 @@
+package mcstatusgo
+
+import (
+	"testing"
+)
+
+func TestMOTD(t *testing.T) {
+	status := parseTestDocument(t, `{"description":{"text":"§aA §lMinecraft","extra":[{"text":" Server"}]},"players":{"max":20,"online":0},"version":{"name":"1.20.1","protocol":763}}`)
+
+	tests := []struct {
+		name   string
+		status ServerStatus
+	}{
+		{"status", status},
+		{"legacy", StatusLegacyResponse{Description: "§aA §lMinecraft§r Server"}},
+		{"beta", StatusBetaResponse{Description: "§aA §lMinecraft§r Server§"}},
+		{"auto status", StatusAutoResponse{Protocol: ProtocolStatus, Description: status.Description, Status: &status}},
+		{"auto legacy", StatusAutoResponse{Protocol: ProtocolStatusLegacy, Description: "§aA §lMinecraft§r Server"}},
+	}
+
+	for _, test := range tests {
+		motd := test.status.MOTD()
+		if motd != "A Minecraft Server" {
+			t.Errorf("%s: MOTD() = %q, want %q", test.name, motd, "A Minecraft Server")
+		}
+	}
+}
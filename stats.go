@@ -0,0 +1,57 @@
+package mcstatusgo
+
+import (
+	"net"
+)
+
+// Stats contains diagnostic information about the traffic of a request.
+//
+// For the query protocols, a response filling the read buffer reveals truncation.
+// For the status protocols, a ReadCount larger than expected reveals chunked reads.
+type Stats struct {
+	// BytesSent contains the number of bytes written to the connection.
+	BytesSent int
+
+	// BytesReceived contains the number of bytes read from the connection.
+	BytesReceived int
+
+	// ReadCount contains the number of reads from the connection that returned data or an error.
+	ReadCount int
+}
+
+// statsConn records the traffic of the net.Conn it wraps into stats.
+type statsConn struct {
+	net.Conn
+	stats *Stats
+}
+
+// Read reads from the wrapped net.Conn and records the read into stats.
+func (c statsConn) Read(b []byte) (int, error) {
+	bytesRead, err := c.Conn.Read(b)
+	c.stats.BytesReceived += bytesRead
+	c.stats.ReadCount++
+
+	return bytesRead, err
+}
+
+// Write writes to the wrapped net.Conn and records the written bytes into stats.
+func (c statsConn) Write(b []byte) (int, error) {
+	bytesWritten, err := c.Conn.Write(b)
+	c.stats.BytesSent += bytesWritten
+
+	return bytesWritten, err
+}
+
+// add adds the traffic statistics of other to s.
+func (s *Stats) add(other Stats) {
+	s.BytesSent += other.BytesSent
+	s.BytesReceived += other.BytesReceived
+	s.ReadCount += other.ReadCount
+}
+
+// recordStats copies the traffic statistics of the request into the Stats given with WithStats, if any.
+func (o options) recordStats(stats *Stats) {
+	if o.stats != nil {
+		*o.stats = *stats
+	}
+}
@@ -0,0 +1,48 @@
+package mcstatusgo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStatusBytes(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	status, err := Status("127.0.0.1", port, testTimeout, testTimeout)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.BytesSent == 0 || status.BytesReceived == 0 {
+		t.Errorf("bytes = %d/%d without WithStats, want both recorded", status.BytesSent, status.BytesReceived)
+	}
+
+	stats := &Stats{BytesSent: 1000}
+	status, err = Status("127.0.0.1", port, testTimeout, testTimeout, WithStats(stats))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.BytesSent != stats.BytesSent || status.BytesReceived != stats.BytesReceived {
+		t.Errorf("bytes = %d/%d, want the stats %d/%d", status.BytesSent, status.BytesReceived, stats.BytesSent, stats.BytesReceived)
+	}
+}
+
+func TestBytesExcludedFromJSON(t *testing.T) {
+	responses := []interface{}{
+		StatusResponse{BytesSent: 1, BytesReceived: 2},
+		StatusLegacyResponse{BytesSent: 1, BytesReceived: 2},
+		StatusBetaResponse{BytesSent: 1, BytesReceived: 2},
+		BasicQueryResponse{BytesSent: 1, BytesReceived: 2},
+		FullQueryResponse{BytesSent: 1, BytesReceived: 2},
+	}
+
+	for _, response := range responses {
+		encoded, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("%T: json.Marshal: %v", response, err)
+		}
+		if strings.Contains(string(encoded), "Bytes") {
+			t.Errorf("%T encoded as %s, want the byte counts excluded", response, encoded)
+		}
+	}
+}
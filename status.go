@@ -1,23 +1,33 @@
 package mcstatusgo
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf16"
 )
 
 const (
 	// packetID identifies the crafted packet as a status packet.
 	packetID byte = 0x00
-	// protocolVersion identifies the client's version of Minecraft (can be any valid protocol version).
-	protocolVersion byte = 0x2F
+	// protocolVersion identifies the client's version of Minecraft (can be any valid protocol version) unless WithProtocolVersion is given.
+	protocolVersion int = 0x2F
 	// nextState is attached to the end of the handshake packet to signal a request for a status response from the server.
 	nextState byte = 0x01
+	// implausiblePlayerCountFactor is the number of times the online count can exceed the max count before it is considered implausible.
+	implausiblePlayerCountFactor int = 10
+	// statusResponsePrefixSize is the length of the longest prefix checked by isStatusResponsePrefix: two varints of at most 3 bytes, as packets are at most 2097151 bytes, the packet ID, and the opening brace.
+	statusResponsePrefixSize int = 8
+	// kickPacketID identifies the packet as a legacy kick packet, sent by servers that don't support the status protocol.
+	kickPacketID byte = 0xFF
 )
 
 var (
@@ -25,6 +35,8 @@ var (
 	statusRequestPacket []byte = []byte{nextState, packetID}
 	// pingPacket is sent to elicit an identical pong from the server to calculate latency.
 	pingPacket []byte = []byte{0x09, 0x01, 0x07, 0x07, 0x07, 0x07, 0x07, 0x07, 0x07, 0x07}
+	// statusRequiredFields are the fields a status response must contain, in the order they're checked.
+	statusRequiredFields []string = []string{"description", "max players", "online players", "version name", "version protocol"}
 )
 
 // Errors.
@@ -37,11 +49,16 @@ var (
 	ErrLargeVarInt error = errors.New("invalid status response: varint sent by server exceeds size limit")
 	// ErrInvalidPong is returned when the pong response received from the server does not match the ping packet sent to it.
 	ErrInvalidPong error = errors.New("invalid status response: pong sent by server does not match ping packet")
+	// ErrResponseTooLarge is returned when the response size declared by the server exceeds the maximum response size.
+	ErrResponseTooLarge error = errors.New("invalid status response: declared response size exceeds the maximum response size")
+	// ErrServerClosedConnection is returned when the server accepts the handshake but closes the connection without sending any response, as some whitelisted or maintenance-mode servers do.
+	// It wraps io.EOF.
+	ErrServerClosedConnection error = fmt.Errorf("invalid status response: server closed the connection without responding: %w", io.EOF)
 )
 
 // ErrMissingInformation is returned when expected values are not receieved.
 type ErrMissingInformation struct {
-	// "status" or "query".
+	// "status", "status legacy", "status beta", or "query".
 	Protocol string
 	// The name of the value that was missing from the response.
 	MissingValue string
@@ -51,6 +68,28 @@ func (e ErrMissingInformation) Error() string {
 	return fmt.Sprintf("invalid %s response: %s missing from response.", e.Protocol, e.MissingValue)
 }
 
+// ErrImplausiblePlayerCount is returned when player count validation is enabled and the server reports implausible player counts.
+type ErrImplausiblePlayerCount struct {
+	// The number of online players reported by the server.
+	Online int
+	// The maximum number of players reported by the server.
+	Max int
+}
+
+func (e ErrImplausiblePlayerCount) Error() string {
+	return fmt.Sprintf("implausible player count: %d online out of %d max players", e.Online, e.Max)
+}
+
+// ErrServerRejectedStatus is returned when the server responds to the status request with a kick packet, which usually means it only supports an older implementation of the status protocol.
+type ErrServerRejectedStatus struct {
+	// The reason sent by the server in the kick packet.
+	Reason string
+}
+
+func (e ErrServerRejectedStatus) Error() string {
+	return fmt.Sprintf("invalid status response: server rejected status request: %s", e.Reason)
+}
+
 // StatusResponse contains the information from the status request.
 // https://wiki.vg/Server_List_Ping#Response
 type StatusResponse struct {
@@ -60,10 +99,30 @@ type StatusResponse struct {
 	// Port contains the server's port used for communication.
 	Port uint16
 
+	// RemoteAddr contains the address the connection actually landed on, including the port.
+	//
+	// It is excluded from JSON so a server can't fail the request by sending a field with the same name.
+	RemoteAddr net.Addr `json:"-"`
+
+	// BytesSent contains the total number of bytes sent to the server during the request, including the ping.
+	BytesSent int `json:"-"`
+
+	// BytesReceived contains the total number of bytes received from the server during the request, including the pong.
+	BytesReceived int `json:"-"`
+
 	// Latency contains the duration of time waited for the pong.
+	//
+	// Latency is the time to the first byte of the response if the ping was skipped using WithSkipPing, and -1 if the ping failed, in which case PingErr is set.
 	Latency time.Duration
 
+	// PingErr contains the error that caused the ping to fail after the status response was received.
+	//
+	// The latency is measured on a best-effort basis, as some servers and proxies answer the status request but mishandle the ping, so a failed ping doesn't fail the request.
+	PingErr error `json:"-"`
+
 	// Description contains a pretty-print JSON string of the server description.
+	//
+	// Descriptions sent as a plain string are converted into a chat component object containing only the text field.
 	Description string `json:"-"`
 
 	// Favicon contains the base64 encoded PNG image of the server that appears in the server list.
@@ -86,15 +145,71 @@ type StatusResponse struct {
 
 		// Sample contains a random sample of players with their username and uuid currently on the server.
 		Sample []map[string]string
+
+		// SampleTruncated contains whether entries beyond the player sample limit were skipped.
+		SampleTruncated bool
+
+		// SampleBanners contains the entries of Sample with an all-zero or malformed UUID, which servers use to display advertising or MOTD-style lines rather than real players.
+		//
+		// The entries are also left in Sample.
+		SampleBanners []map[string]string
 	}
 
 	ModInfo struct {
 		// Type contains the server mod running on the server.
 		Type string
 
-		// ModList contains the plugins with their versions running on the server.
-		ModList []map[string]string
+		// ModList contains the mods with their versions running on the server, in the order sent by the server.
+		ModList []Mod
+
+		// NetworkVersion contains the FML network protocol version sent by some legacy Forge servers.
+		//
+		// Empty if the server didn't send the field.
+		NetworkVersion string `json:"-"`
 	}
+
+	// EnforcesSecureChat contains whether the server requires clients to sign their chat messages (1.19+).
+	//
+	// nil if the server didn't send the field.
+	EnforcesSecureChat *bool
+
+	// PreviewsChat contains whether the server previews chat messages before they are sent (1.19 to 1.19.2).
+	//
+	// nil if the server didn't send the field.
+	PreviewsChat *bool
+}
+
+// Mod contains a mod or plugin running on the server.
+type Mod struct {
+	// Name contains the name or ID of the mod.
+	Name string `json:"name"`
+
+	// Version contains the version of the mod.
+	//
+	// Empty if the server didn't send a version.
+	Version string `json:"version"`
+}
+
+// UnmarshalJSON unmarshals a mod from the status modinfo, which identifies each mod by its "modid".
+func (m *Mod) UnmarshalJSON(data []byte) error {
+	var modInfo struct {
+		ModID   string
+		Name    string
+		Version string
+	}
+
+	err := json.Unmarshal(data, &modInfo)
+	if err != nil {
+		return err
+	}
+
+	m.Name = modInfo.ModID
+	if m.Name == "" {
+		m.Name = modInfo.Name
+	}
+	m.Version = modInfo.Version
+
+	return nil
 }
 
 // Status requests basic server information from a Minecraft server.
@@ -103,65 +218,314 @@ type StatusResponse struct {
 //
 // If a valid response is received, a StatusResponse is returned.
 // https://wiki.vg/Server_List_Ping
-func Status(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (StatusResponse, error) {
-	serverAndPort := fmt.Sprintf("%s:%d", server, port)
+func Status(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (StatusResponse, error) {
+	return statusDial(server, server, port, initialConnectionTimeout, ioTimeout, newOptions(opts))
+}
+
+// statusDial dials dialServer and performs the status request, sending server in the handshake.
+func statusDial(dialServer string, server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (StatusResponse, error) {
+	options, cancel := options.withOverallTimeout()
+	defer cancel()
 
-	con, err := net.DialTimeout("tcp", serverAndPort, initialConnectionTimeout)
+	con, err := dial("tcp", dialServer, port, initialConnectionTimeout, ProtocolStatus, options)
 	if err != nil {
 		return StatusResponse{}, err
 	}
-	// If the connection closes normally, this line will run but not do anything.
 	defer resetConnection(con)
 
-	// Split the string "IP:PORT" by : to get the IP of the remote host.
-	serverIP := strings.Split(con.RemoteAddr().String(), ":")[0]
+	// A context without a deadline doesn't clamp any io deadline, so the connection is closed to interrupt the request once it is cancelled.
+	stop := closeOnDone(options.context(), con)
+	defer stop()
+
+	return statusConn(con, server, port, ioTimeout, options)
+}
+
+// StatusConn requests basic server information from a Minecraft server over an already established connection.
+//
+// server and port are only used for the handshake and the response, as the connection is neither dialed nor closed.
+// The lifecycle of con is left to the caller.
+//
+// If a valid response is received, a StatusResponse is returned.
+// https://wiki.vg/Server_List_Ping
+func StatusConn(con net.Conn, server string, port uint16, ioTimeout time.Duration, opts ...Option) (StatusResponse, error) {
+	options, cancel := newOptions(opts).withOverallTimeout()
+	defer cancel()
+
+	return statusConn(con, server, port, ioTimeout, options)
+}
+
+// statusConn performs the status request over con.
+func statusConn(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (StatusResponse, error) {
+	con, stats := options.wrapConn(con, ProtocolStatus)
+	startTime := time.Now()
 
-	err = initiateStatusRequest(con, ioTimeout, server, port)
+	status, err := performStatusRequest(con, server, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolStatus, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+	if err == nil {
+		status.BytesSent = stats.BytesSent
+		status.BytesReceived = stats.BytesReceived
+	}
+
+	return status, err
+}
+
+// performStatusRequest performs the status request over con.
+func performStatusRequest(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (StatusResponse, error) {
+	serverIP := remoteIP(con)
+
+	exchange, err := requestStatus(con, server, port, ioTimeout, options)
 	if err != nil {
 		return StatusResponse{}, err
 	}
 
-	response, err := readStatusResponse(con, ioTimeout)
+	status, err := packageStatusResponse(serverIP, port, exchange.latency, exchange.response, options.playerSampleLimit)
+	options.traceOutcome("status", err)
 	if err != nil {
 		return StatusResponse{}, err
 	}
+	status.RemoteAddr = con.RemoteAddr()
+	status.PingErr = exchange.pingErr
 
-	latency, err := calculateLatency(con, ioTimeout)
+	err = options.validatePlayerCounts(status.Players.Online, status.Players.Max)
 	if err != nil {
 		return StatusResponse{}, err
 	}
 
-	con.Close()
+	return status, nil
+}
 
-	status, err := packageStatusResponse(serverIP, port, latency, response)
+// statusExchange contains the raw status response along with the outcome of the ping that followed it.
+type statusExchange struct {
+	// response is the raw status response.
+	response []byte
+	// latency is the time to the first byte of the response if the ping was skipped, and -1 if the ping failed.
+	latency time.Duration
+	// pingErr is the error that caused the ping to fail, which doesn't fail the request.
+	pingErr error
+}
+
+// requestStatus sends the status request, receives the raw response, and measures the latency unless the ping is skipped.
+//
+// The latency is measured on a best-effort basis, so a failed ping is recorded in the exchange rather than returned.
+func requestStatus(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (statusExchange, error) {
+	err := options.writeProxyHeader(con, ioTimeout)
 	if err != nil {
-		return StatusResponse{}, err
+		return statusExchange{}, err
 	}
 
-	return status, nil
+	timer := options.startLatencyTimer()
+	err = initiateStatusRequest(con, ioTimeout, options.advertisedProtocolVersion(), options.handshakeHostname(server), options.advertisedPort(port))
+	if err != nil {
+		return statusExchange{}, err
+	}
+
+	// Read the whole response through one buffered reader to avoid reading the varints a byte at a time from the connection.
+	reader := bufio.NewReaderSize(con, options.readBufferSizeOr(statusReadBufferSize))
+
+	exchange := statusExchange{latency: -1}
+	if options.skipPing {
+		// Without the ping, the latency is the time to the first byte of the response.
+		err = awaitStatusResponse(con, reader, ioTimeout)
+		if err != nil {
+			return statusExchange{}, err
+		}
+		exchange.latency = timer.elapsed()
+	}
+
+	exchange.response, err = readStatusResponse(con, reader, ioTimeout, options.maxResponseSize)
+	if err != nil {
+		return statusExchange{}, err
+	}
+	options.trace("status response: %d bytes", len(exchange.response))
+
+	if !options.skipPing {
+		latency, err := calculateLatency(con, reader, options.statusPongTimeout(ioTimeout), options)
+		if err != nil {
+			options.trace("status ping failed: %v", err)
+			exchange.pingErr = classifyNetworkError(err)
+		} else {
+			exchange.latency = latency
+		}
+	}
+
+	return exchange, nil
+}
+
+// StatusRaw requests the status response from a Minecraft server and returns its JSON document without parsing it, along with the latency.
+//
+// This allows unmarshalling non-standard fields sent by servers, such as vendor extensions, into a custom struct.
+// The length of the document is checked and it is verified to be valid JSON, but its fields aren't validated.
+// The latency is the time to the first byte of the response if the ping was skipped, and -1 if the ping failed.
+// https://wiki.vg/Server_List_Ping#Response
+func StatusRaw(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (json.RawMessage, time.Duration, error) {
+	options, cancel := newOptions(opts).withOverallTimeout()
+	defer cancel()
+
+	con, err := dial("tcp", server, port, initialConnectionTimeout, ProtocolStatus, options)
+	if err != nil {
+		return nil, -1, err
+	}
+	defer resetConnection(con)
+
+	con, stats := options.wrapConn(con, ProtocolStatus)
+	startTime := time.Now()
+
+	document, latency, err := performStatusRawRequest(con, server, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolStatus, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+
+	return document, latency, err
+}
+
+// performStatusRawRequest performs the status request over con, returning the JSON document without parsing it.
+func performStatusRawRequest(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (json.RawMessage, time.Duration, error) {
+	exchange, err := requestStatus(con, server, port, ioTimeout, options)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	formatedResponse, err := formatStatusResponse(exchange.response)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	var document json.RawMessage
+	err = json.Unmarshal(formatedResponse, &document)
+	options.traceOutcome("raw status", err)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return document, exchange.latency, nil
 }
 
 // Ping serves as a convenience wrapper over Status to retrieve the server latency.
 //
 // Retrieving the latency from a StatusResponse provides the same function.
 // https://wiki.vg/Server_List_Ping#Ping
-func Ping(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (time.Duration, error) {
-	status, err := Status(server, port, initialConnectionTimeout, ioTimeout)
+func Ping(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (time.Duration, error) {
+	status, err := Status(server, port, initialConnectionTimeout, ioTimeout, opts...)
 	if err != nil {
 		return -1, err
 	}
+	if status.PingErr != nil {
+		return -1, status.PingErr
+	}
 
 	return status.Latency, nil
 }
 
+// checkPlayerCounts returns ErrImplausiblePlayerCount if either player count is negative or the online count exceeds the max count by an implausible margin.
+func checkPlayerCounts(online int, max int) error {
+	if online < 0 || max < 0 {
+		return ErrImplausiblePlayerCount{online, max}
+	}
+
+	if max > 0 && online > max*implausiblePlayerCountFactor {
+		return ErrImplausiblePlayerCount{online, max}
+	}
+
+	return nil
+}
+
+// dial is used by all protocols for connecting to the server.
+//
+// The server and port are validated first, returning ErrInvalidArgument if either can't be dialed.
+// Internationalized hostnames are converted into their ASCII form before being resolved.
+func dial(network string, server string, port uint16, timeout time.Duration, protocol StatusProtocol, options options) (net.Conn, error) {
+	startTime := time.Now()
+
+	err := validateServerAddr(server, port)
+	if err != nil {
+		options.hookError(protocol, err)
+		return nil, err
+	}
+
+	ipVersion, err := options.ipVersion()
+	if err != nil {
+		options.hookError(protocol, err)
+		return nil, err
+	}
+	network += ipVersion
+
+	con, err := dialServer(network, server, port, timeout, options)
+	if err != nil {
+		err = classifyNetworkError(err)
+		options.hookError(protocol, err)
+		return nil, err
+	}
+	options.hookDial(con.RemoteAddr(), time.Since(startTime))
+
+	return con, nil
+}
+
+// dialServer converts the server's hostname into its ASCII form and dials it.
+func dialServer(network string, server string, port uint16, timeout time.Duration, options options) (net.Conn, error) {
+	asciiServer, err := toASCIIHostname(server)
+	if err != nil {
+		return nil, err
+	}
+	serverAndPort := net.JoinHostPort(asciiServer, strconv.Itoa(int(port)))
+
+	dialer := net.Dialer{
+		Timeout:   timeout,
+		LocalAddr: options.localAddrFor(network),
+		Resolver:  options.netResolver(),
+		KeepAlive: options.keepAlive(),
+	}
+
+	con, err := dialer.DialContext(options.context(), network, serverAndPort)
+	if err != nil {
+		return nil, err
+	}
+
+	err = options.configureTCPConn(con)
+	if err != nil {
+		con.Close()
+		return nil, err
+	}
+
+	return con, nil
+}
+
+// remoteIP is used by all protocols for retrieving the IP of the remote host from con.
+func remoteIP(con net.Conn) string {
+	remoteAddr := con.RemoteAddr().String()
+
+	serverIP, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		// The address doesn't contain a port, as with some custom connections.
+		return remoteAddr
+	}
+
+	return serverIP
+}
+
 // resetConnection sends an RST packet to terminate the connection immediately.
+//
+// It is the only path that closes the connections dialed by the status protocols and is safe to call on an already closed connection.
 func resetConnection(con net.Conn) {
-	TCPCon := (con).(*net.TCPConn)
-	TCPCon.SetLinger(0)
+	TCPCon, ok := con.(*net.TCPConn)
+	if !ok {
+		con.Close()
+		return
+	}
+
+	// SetLinger fails if the connection is already closed, in which case there is nothing left to reset.
+	err := TCPCon.SetLinger(0)
+	if err != nil {
+		return
+	}
 	TCPCon.Close()
 }
 
 // setDeadline is used by all protocols for setting the deadline (duration waited) for io operations.
+//
+// Each step of a request sets a fresh deadline, so a request can take longer than timeout in total unless WithOverallTimeout is given.
+// If the request has a context, con is a contextConn that clamps the deadline to the deadline of the context.
 func setDeadline(con *net.Conn, timeout time.Duration) {
 	timeDeadline := time.Now().Add(timeout)
 	(*con).SetDeadline(timeDeadline)
@@ -176,36 +540,48 @@ func initiateRequest(con net.Conn, timeout time.Duration, requestPacket []byte)
 }
 
 // initiateStatusRequest handles sending the handshake and request packets.
-func initiateStatusRequest(con net.Conn, timeout time.Duration, server string, port uint16) error {
-	handshake := createStatusHandshakePacket(server, port)
-	completedRequestPacket := append(handshake, statusRequestPacket...)
+func initiateStatusRequest(con net.Conn, timeout time.Duration, protocol int, server string, port uint16) error {
+	// The buffer fits both packets, so building them doesn't reallocate.
+	requestPacket := make([]byte, 0, statusHandshakePacketSize(protocol, server)+len(statusRequestPacket))
+	requestPacket = appendStatusHandshakePacket(requestPacket, protocol, server, port)
+	requestPacket = append(requestPacket, statusRequestPacket...)
 
-	err := initiateRequest(con, timeout, completedRequestPacket)
+	err := initiateRequest(con, timeout, requestPacket)
 
 	return err
 }
 
 // createStatusHandshakePacket crafts the handshake packet used to initialize the connection with the server.
 // https://wiki.vg/Server_List_Ping#Handshake
-func createStatusHandshakePacket(server string, port uint16) []byte {
-	handshake := []byte{packetID, protocolVersion}
-	handshake = append(handshake, serverToBytes(server)...)
-	handshake = append(handshake, portToBytes(port)...)
-	handshake = append(handshake, nextState)
+func createStatusHandshakePacket(protocol int, server string, port uint16) []byte {
+	return appendStatusHandshakePacket(make([]byte, 0, statusHandshakePacketSize(protocol, server)), protocol, server, port)
+}
 
-	// Prepend handshake with varint containing the length of the handshake.
-	handshake = append(writeVarInt(len(handshake)), handshake...)
+// appendStatusHandshakePacket appends the handshake packet to dst and returns the extended slice, allocating nothing if dst has enough capacity.
+// https://wiki.vg/Server_List_Ping#Handshake
+func appendStatusHandshakePacket(dst []byte, protocol int, server string, port uint16) []byte {
+	// The handshake is prepended with a varint containing its length, which is calculated up front so the handshake can be written in one pass.
+	dst = AppendVarInt(dst, statusHandshakeSize(protocol, server))
+	dst = append(dst, packetID)
+	dst = AppendVarInt(dst, protocol)
+	dst = AppendVarInt(dst, len(server))
+	dst = append(dst, server...)
+	dst = append(dst, byte(port>>8), byte(port))
+
+	return append(dst, nextState)
+}
 
-	return handshake
+// statusHandshakeSize returns the length of the handshake, excluding the varint containing it.
+func statusHandshakeSize(protocol int, server string) int {
+	// The packet ID, the server, the port, and the next state.
+	return 1 + varIntSize(protocol) + varIntSize(len(server)) + len(server) + 2 + 1
 }
 
-// serverToBytes converts a server string into its []byte equivalent and prepends it with a varint containing its length.
-func serverToBytes(server string) []byte {
-	serverInBytes := []byte(server)
-	serverLength := writeVarInt(len(serverInBytes))
-	serverInBytesWithLength := append(serverLength, serverInBytes...)
+// statusHandshakePacketSize returns the length of the handshake packet, including the varint containing the length of the handshake.
+func statusHandshakePacketSize(protocol int, server string) int {
+	handshakeSize := statusHandshakeSize(protocol, server)
 
-	return serverInBytesWithLength
+	return varIntSize(handshakeSize) + handshakeSize
 }
 
 // portToBytes converts a uint16 port number to its []byte equivalent.
@@ -216,104 +592,148 @@ func portToBytes(port uint16) []byte {
 	return portInBytes
 }
 
-// writeVarInt converts an int into its varint []byte equivalent.
-// https://wiki.vg/Protocol#VarInt_and_VarLong
-func writeVarInt(number int) []byte {
-	varInt := []byte{}
-
-	for {
-		var currentByte byte
+// readStatusResponse receives the full status response from the server.
+//
+// ErrServerClosedConnection is returned if the server closes the connection before sending a single byte.
+func readStatusResponse(con net.Conn, reader *bufio.Reader, timeout time.Duration, maxResponseSize int) ([]byte, error) {
+	err := awaitStatusResponse(con, reader, timeout)
+	if err != nil {
+		return nil, err
+	}
 
-		// No more bytes in the varint.
-		if number&0xFFFFFF80 == 0 {
-			currentByte = byte(number & 0x7F)
-			varInt = append(varInt, currentByte)
-			break
-		}
+	setDeadline(&con, timeout)
+	err = checkKickPacket(reader, maxResponseSize)
+	if err != nil {
+		return nil, err
+	}
 
-		currentByte = byte((number & 0x7F) | 0x80)
-		varInt = append(varInt, currentByte)
+	responseSize, err := readStatusResponseSize(con, reader, timeout)
+	if err != nil {
+		return nil, err
+	}
 
-		number >>= 7
+	// Refuse to read responses declared larger than the maximum before receiving any of it.
+	if responseSize > maxResponseSize {
+		return nil, ErrResponseTooLarge
+	}
+	if responseSize < 0 {
+		return nil, ErrInvalidSizeInfo
 	}
 
-	return varInt
-}
+	// The buffer is allocated only after the declared size has been checked, so hostile sizes can't cause huge allocations.
+	response := make([]byte, responseSize)
 
-// readStatusResponse receives the full status response from the server.
-func readStatusResponse(con net.Conn, timeout time.Duration) ([]byte, error) {
-	responseSize, err := readStatusResponseSize(con, timeout)
+	// Keep receiving bytes until the full message is received.
+	setDeadline(&con, timeout)
+	_, err = io.ReadFull(reader, response)
 	if err != nil {
 		return nil, err
 	}
 
-	response := []byte{}
+	return response, nil
+}
 
-	// Keep receiving bytes until the full message is received.
+// awaitStatusResponse waits until the first byte of the response is received.
+func awaitStatusResponse(con net.Conn, reader *bufio.Reader, timeout time.Duration) error {
 	setDeadline(&con, timeout)
-	for len(response) < responseSize {
-		recvBuffer := make([]byte, 4096)
-		bytesRead, err := con.Read(recvBuffer)
+	_, err := reader.Peek(1)
+	if errors.Is(err, io.EOF) {
+		return ErrServerClosedConnection
+	}
 
-		if err != nil {
-			return nil, err
+	return err
+}
+
+// checkKickPacket returns ErrServerRejectedStatus containing the kick reason if the server responded with a kick packet.
+// https://wiki.vg/Protocol#Disconnect_.28login.29
+func checkKickPacket(reader *bufio.Reader, maxResponseSize int) error {
+	// A kick packet starts with its packet ID followed by a short containing the reason length.
+	header, err := reader.Peek(2)
+	if err != nil {
+		return err
+	}
+	if header[0] != kickPacketID {
+		return nil
+	}
+
+	// A reason shorter than 256 characters makes the status response begin with an overlong varint, so only longer reasons need the response prefix checked.
+	if header[1] != 0x00 {
+		// Both a kick packet with such a reason and a status response starting with 0xFF are far longer than the prefix.
+		prefix, err := reader.Peek(statusResponsePrefixSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
 		}
+		if isStatusResponsePrefix(prefix) {
+			return nil
+		}
+	}
 
-		response = append(response, recvBuffer[0:bytesRead]...)
+	header = make([]byte, 3)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		return err
 	}
 
-	return response, nil
-}
+	// The reason length is the number of UTF-16 characters in the reason.
+	reasonSize := int(binary.BigEndian.Uint16(header[1:])) * 2
+	if reasonSize > maxResponseSize {
+		return ErrResponseTooLarge
+	}
 
-// readResponseSize reads and parses the varint that prepends the server's response which contains the length of the response.
-func readStatusResponseSize(con net.Conn, timeout time.Duration) (int, error) {
-	varInt := []byte{}
+	reason := make([]byte, reasonSize)
+	_, err = io.ReadFull(reader, reason)
+	if err != nil {
+		return err
+	}
 
-	setDeadline(&con, timeout)
-	for {
-		recvBuffer := make([]byte, 1)
-		_, err := con.Read(recvBuffer)
+	return ErrServerRejectedStatus{decodeUTF16BE(reason)}
+}
 
-		if err != nil {
-			return -1, err
-		}
+// isStatusResponsePrefix reports whether prefix is the start of a status response: the packet length, the packet ID, the JSON length, and the opening brace of the JSON.
+func isStatusResponsePrefix(prefix []byte) bool {
+	reader := bytes.NewReader(prefix)
 
-		// Varint has terminated.
-		if recvBuffer[0]&0x80 == 0 {
-			varInt = append(varInt, recvBuffer[0])
-			break
-		}
-		varInt = append(varInt, recvBuffer[0])
+	_, err := readVarIntFrom(reader)
+	if err != nil {
+		return false
 	}
 
-	return readVarInt(varInt)
-}
+	id, err := reader.ReadByte()
+	if err != nil || id != packetID {
+		return false
+	}
 
-// readVarInt converts a varint into its int equivalent.
-// https://wiki.vg/Protocol#VarInt_and_VarLong
-func readVarInt(varInt []byte) (int, error) {
-	number := 0
-	bitOffSet := 0
+	jsonLength, err := readVarIntFrom(reader)
+	if err != nil || jsonLength <= 0 {
+		return false
+	}
 
-	for _, currentByte := range varInt {
-		if bitOffSet == 35 {
-			return -1, ErrLargeVarInt
-		}
+	opening, err := reader.ReadByte()
 
-		number |= int(currentByte&0x7F) << bitOffSet
+	return err == nil && opening == '{'
+}
 
-		if currentByte&0x80 == 0 {
-			break
-		}
-		bitOffSet += 7
+// decodeUTF16BE decodes a UTF-16BE encoded byte string.
+func decodeUTF16BE(encoded []byte) string {
+	characters := make([]uint16, len(encoded)/2)
+	for i := range characters {
+		characters[i] = binary.BigEndian.Uint16(encoded[i*2:])
 	}
 
-	return number, nil
+	return string(utf16.Decode(characters))
+}
+
+// readStatusResponseSize reads and parses the varint that prepends the server's response which contains the length of the response.
+func readStatusResponseSize(con net.Conn, reader *bufio.Reader, timeout time.Duration) (int, error) {
+	setDeadline(&con, timeout)
+
+	return readVarIntFrom(reader)
 }
 
 // calculateLatency measures the duration of time waited for a pong from the server.
-func calculateLatency(con net.Conn, timeout time.Duration) (time.Duration, error) {
+func calculateLatency(con net.Conn, reader *bufio.Reader, timeout time.Duration, options options) (time.Duration, error) {
 	setDeadline(&con, timeout)
+	timer := options.startLatencyTimer()
 	_, err := con.Write(pingPacket)
 	if err != nil {
 		return -1, err
@@ -322,12 +742,11 @@ func calculateLatency(con net.Conn, timeout time.Duration) (time.Duration, error
 	pong := make([]byte, 10)
 	setDeadline(&con, timeout)
 
-	startTime := time.Now()
-	_, err = con.Read(pong)
+	_, err = io.ReadFull(reader, pong)
 	if err != nil {
 		return -1, err
 	}
-	latency := time.Since(startTime)
+	latency := timer.elapsed()
 
 	if !bytes.Equal(pingPacket, pong) {
 		return -1, ErrInvalidPong
@@ -337,7 +756,9 @@ func calculateLatency(con net.Conn, timeout time.Duration) (time.Duration, error
 }
 
 // packageStatusResponse formats, parses, and packages the response into status.
-func packageStatusResponse(serverIP string, port uint16, latency time.Duration, response []byte) (StatusResponse, error) {
+//
+// At most sampleLimit player sample entries are parsed, or every entry if sampleLimit is negative.
+func packageStatusResponse(serverIP string, port uint16, latency time.Duration, response []byte, sampleLimit int) (StatusResponse, error) {
 	status := StatusResponse{}
 	status.IP = serverIP
 	status.Port = port
@@ -355,21 +776,50 @@ func packageStatusResponse(serverIP string, port uint16, latency time.Duration,
 	}
 
 	// Unmarshal the formatted JSON response into status.
-	err = json.Unmarshal(formatedResponse, &status)
+	// The players are unmarshalled separately so the player sample can be limited.
+	var statusInfo struct {
+		*StatusResponse
+		Players struct {
+			Max, Online flexibleInt
+			Sample      limitedPlayerSample
+		}
+	}
+	statusInfo.StatusResponse = &status
+	statusInfo.Players.Sample.limit = sampleLimit
+
+	err = json.Unmarshal(formatedResponse, &statusInfo)
 	if err != nil {
 		return StatusResponse{}, err
 	}
 
+	status.Players.Max = int(statusInfo.Players.Max)
+	status.Players.Online = int(statusInfo.Players.Online)
+	status.Players.Sample = statusInfo.Players.Sample.players
+	status.Players.SampleTruncated = statusInfo.Players.Sample.truncated
+	status.Players.SampleBanners = sampleBanners(status.Players.Sample)
+
 	// Add the description information to status.
 	err = packageDescription(formatedResponse, &status)
 	if err != nil {
 		return StatusResponse{}, err
 	}
 
+	err = packageNetworkVersion(formatedResponse, &status)
+	if err != nil {
+		return StatusResponse{}, err
+	}
+
+	err = packageForgeData(formatedResponse, &status)
+	if err != nil {
+		return StatusResponse{}, err
+	}
+
 	return status, nil
 }
 
 // formatResponse cleans the response for JSON processing.
+//
+// Trailing bytes after the declared JSON length are tolerated and dropped, but a JSON string shorter than declared is rejected.
 func formatStatusResponse(response []byte) ([]byte, error) {
 	if len(response) < 4 {
 		return nil, ErrShortStatusResponse
@@ -392,17 +842,18 @@ func formatStatusResponse(response []byte) ([]byte, error) {
 	response = response[len(jsonLen):]
 
 	// Parse JSON string length to an int.
-	jsonLength, err := readVarInt(jsonLen)
+	jsonLength, err := ReadVarInt(jsonLen)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if JSON size information matches the size of the JSON string.
-	if jsonLength != len(response) {
+	// Check if the JSON string is at least as long as its size information declares.
+	if jsonLength < 0 || jsonLength > len(response) {
 		return nil, ErrInvalidSizeInfo
 	}
 
-	return response, nil
+	// Ignore any padding after the JSON string.
+	return response[:jsonLength], nil
 }
 
 // validateStatusResponse checks for missing information from the status response.
@@ -419,27 +870,32 @@ func validateStatusResponse(response []byte) error {
 		return err
 	}
 
-	// Check if any of the values were left out from the status response.
-	if verifyResponse.Description == nil {
-		return ErrMissingInformation{"status", "description"}
-	}
-	if verifyResponse.Players.Max == nil {
-		return ErrMissingInformation{"status", "max players"}
+	present := map[string]bool{
+		"description":      verifyResponse.Description != nil,
+		"max players":      verifyResponse.Players.Max != nil,
+		"online players":   verifyResponse.Players.Online != nil,
+		"version name":     verifyResponse.Version.Name != nil,
+		"version protocol": verifyResponse.Version.Protocol != nil,
 	}
-	if verifyResponse.Players.Online == nil {
-		return ErrMissingInformation{"status", "online players"}
-	}
-	if verifyResponse.Version.Name == nil {
-		return ErrMissingInformation{"status", "version name"}
-	}
-	if verifyResponse.Version.Protocol == nil {
-		return ErrMissingInformation{"status", "version protocol"}
+
+	// Check if any of the values were left out from the status response.
+	return checkRequiredFields("status", statusRequiredFields, func(field string) bool { return present[field] })
+}
+
+// checkRequiredFields returns ErrMissingInformation for the first of requiredFields that present reports as left out from the response to request.
+func checkRequiredFields(request string, requiredFields []string, present func(field string) bool) error {
+	for _, field := range requiredFields {
+		if !present(field) {
+			return ErrMissingInformation{request, field}
+		}
 	}
 
 	return nil
 }
 
 // packageDescription parses the description into a pretty-print JSON string and packages it into status.
+//
+// Both the chat component object and the plain string forms of the description are packaged as a chat component object.
 func packageDescription(response []byte, status *StatusResponse) error {
 	var descriptionInfo struct {
 		Description interface{}
@@ -450,7 +906,9 @@ func packageDescription(response []byte, status *StatusResponse) error {
 		return err
 	}
 
-	descJSONBytes, err := json.MarshalIndent(descriptionInfo.Description, "", "  ")
+	description := normalizeDescription(descriptionInfo.Description)
+
+	descJSONBytes, err := json.MarshalIndent(description, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -458,4 +916,117 @@ func packageDescription(response []byte, status *StatusResponse) error {
 	status.Description = string(descJSONBytes)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// normalizeDescription converts a description sent as a plain string into its chat component object equivalent.
+//
+// Descriptions of unexpected types, such as numbers or booleans sent by broken servers, are coerced into a string first.
+// https://wiki.vg/Chat
+func normalizeDescription(description interface{}) interface{} {
+	switch description := description.(type) {
+	case string:
+		return map[string]string{"text": description}
+	case map[string]interface{}, []interface{}:
+		return description
+	default:
+		return map[string]string{"text": fmt.Sprint(description)}
+	}
+}
+
+// packageNetworkVersion packages the FML network version from the modinfo into status.
+//
+// The version is sent as either a string or a number depending on the server, so both forms are packaged as a string.
+func packageNetworkVersion(response []byte, status *StatusResponse) error {
+	var modInfo struct {
+		ModInfo struct {
+			NetworkVersion json.RawMessage `json:"fml_network_version"`
+		}
+	}
+
+	err := json.Unmarshal(response, &modInfo)
+	if err != nil {
+		return err
+	}
+
+	networkVersion := modInfo.ModInfo.NetworkVersion
+	if len(networkVersion) == 0 || string(networkVersion) == "null" {
+		return nil
+	}
+
+	var version string
+	err = json.Unmarshal(networkVersion, &version)
+	if err != nil {
+		// The version was sent as a number.
+		version = string(networkVersion)
+	}
+	status.ModInfo.NetworkVersion = version
+
+	return nil
+}
+
+// limitedPlayerSample unmarshals a player sample while skipping the entries beyond limit.
+type limitedPlayerSample struct {
+	limit     int
+	players   []map[string]string
+	truncated bool
+}
+
+// UnmarshalJSON decodes the sample entries one at a time so the skipped entries are never allocated as players.
+func (l *limitedPlayerSample) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Read the opening bracket of the array.
+	_, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	players := []map[string]string{}
+	for decoder.More() {
+		if l.limit >= 0 && len(players) >= l.limit {
+			l.truncated = true
+			break
+		}
+
+		var player map[string]string
+		err = decoder.Decode(&player)
+		if err != nil {
+			return err
+		}
+		players = append(players, player)
+	}
+	l.players = players
+
+	return nil
+}
+
+// flexibleInt unmarshals an int sent as either a JSON number or a JSON string, as some servers send numeric strings where ints are expected.
+type flexibleInt int
+
+// UnmarshalJSON unmarshals the number or the numeric string into f.
+func (f *flexibleInt) UnmarshalJSON(data []byte) error {
+	var numString string
+	err := json.Unmarshal(data, &numString)
+	if err != nil {
+		var num int
+		err = json.Unmarshal(data, &num)
+		if err != nil {
+			return err
+		}
+
+		*f = flexibleInt(num)
+		return nil
+	}
+
+	num, err := stringToInt(strings.TrimSpace(numString))
+	if err != nil {
+		return err
+	}
+	*f = flexibleInt(num)
+
+	return nil
+}
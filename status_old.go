@@ -1,13 +1,13 @@
 package mcstatusgo
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
-	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
+	"unicode/utf16"
 )
 
 // This file contains all older implementations of the status protocol.
@@ -15,10 +15,21 @@ import (
 /* Status Legacy */
 
 var (
-	// legacyRequestPacket is the packet sent to elicit a legacy status response from the server.
+	// legacyRequestPacket begins the packet sent to elicit a legacy status response from the server, and is followed by the MC|PingHost plugin message data.
 	legacyRequestPacket []byte = []byte{0xFE, 0x01, 0xFA}
 )
 
+const (
+	// legacyValueSplit contains the character that each value is separated with in the decoded response.
+	legacyValueSplit string = "\x00"
+	// legacyResponsePrefix begins the decoded response of servers running 1.4 or newer, including current servers that still answer legacy pings.
+	legacyResponsePrefix string = "§1\x00"
+	// legacyPingHostChannel is the plugin channel the hostname and port are sent on.
+	legacyPingHostChannel string = "MC|PingHost"
+	// legacyProtocolVersion is the protocol version sent in the legacy request (74 for 1.6.2).
+	legacyProtocolVersion byte = 0x4A
+)
+
 // Errors.
 var (
 	// ErrShortStatusLegacyResponse is returned when the received response is too small to contain valid data.
@@ -36,6 +47,17 @@ type StatusLegacyResponse struct {
 	// Port contains the server's port used for communication.
 	Port uint16
 
+	// RemoteAddr contains the address the connection actually landed on, including the port.
+	//
+	// It is excluded from JSON like the RemoteAddr of StatusResponse.
+	RemoteAddr net.Addr `json:"-"`
+
+	// BytesSent contains the total number of bytes sent to the server during the request.
+	BytesSent int `json:"-"`
+
+	// BytesReceived contains the total number of bytes received from the server during the request.
+	BytesReceived int `json:"-"`
+
 	// Latency contains the duration of time waited for the response.
 	Latency time.Duration
 
@@ -65,32 +87,64 @@ type StatusLegacyResponse struct {
 //
 // If a valid response is received, a StatusLegacyResponse is returned.
 // https://wiki.vg/Server_List_Ping#1.6
-func StatusLegacy(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (StatusLegacyResponse, error) {
-	serverAndPort := fmt.Sprintf("%s:%d", server, port)
+func StatusLegacy(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (StatusLegacyResponse, error) {
+	return statusLegacyDial(server, server, port, initialConnectionTimeout, ioTimeout, newOptions(opts))
+}
 
-	con, err := net.DialTimeout("tcp", serverAndPort, initialConnectionTimeout)
+// statusLegacyDial dials dialServer and performs the legacy status request, sending server in the MC|PingHost plugin message.
+func statusLegacyDial(dialServer string, server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (StatusLegacyResponse, error) {
+	con, err := dial("tcp", dialServer, port, initialConnectionTimeout, ProtocolStatusLegacy, options)
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
-	// If the connection closes normally, this line will run but not do anything.
 	defer resetConnection(con)
 
-	// Split the string "IP:PORT" by : to get the IP of the remote host.
-	serverIP := strings.Split(con.RemoteAddr().String(), ":")[0]
+	con, stats := options.wrapConn(con, ProtocolStatusLegacy)
+	startTime := time.Now()
 
-	err = initiateRequest(con, ioTimeout, legacyRequestPacket)
+	statusLegacy, err := performStatusLegacyRequest(con, server, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolStatusLegacy, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+	if err == nil {
+		statusLegacy.BytesSent = stats.BytesSent
+		statusLegacy.BytesReceived = stats.BytesReceived
+	}
+
+	return statusLegacy, err
+}
+
+// performStatusLegacyRequest performs the legacy status request over con.
+func performStatusLegacyRequest(con net.Conn, server string, port uint16, ioTimeout time.Duration, options options) (StatusLegacyResponse, error) {
+	serverIP := remoteIP(con)
+
+	err := options.writeProxyHeader(con, ioTimeout)
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
 
-	response, latency, err := readLegacyStatusResponse(con, ioTimeout)
+	requestPacket := createLegacyRequestPacket(options.advertisedHostname(server), options.advertisedPort(port))
+
+	timer := options.startLatencyTimer()
+	err = initiateRequest(con, ioTimeout, requestPacket)
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
 
-	con.Close()
+	response, latency, err := readLegacyStatusResponse(con, ioTimeout, options.maxResponseSize, timer)
+	if err != nil {
+		return StatusLegacyResponse{}, err
+	}
+	options.trace("legacy status response: %d bytes", len(response))
 
 	statusLegacy, err := packageLegacyStatusResponse(serverIP, port, latency, response)
+	options.traceOutcome("legacy status", err)
+	if err != nil {
+		return StatusLegacyResponse{}, err
+	}
+	statusLegacy.RemoteAddr = con.RemoteAddr()
+
+	err = options.validatePlayerCounts(statusLegacy.Players.Online, statusLegacy.Players.Max)
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
@@ -98,21 +152,69 @@ func StatusLegacy(server string, port uint16, initialConnectionTimeout time.Dura
 	return statusLegacy, nil
 }
 
-// readLegacyStatusResponse receives the full legacy status response from the server.
-func readLegacyStatusResponse(con net.Conn, timeout time.Duration) ([]byte, time.Duration, error) {
-	response := make([]byte, 512)
+// createLegacyRequestPacket crafts the legacy request packet, including the MC|PingHost plugin message containing the hostname and port the client connected to.
+// https://wiki.vg/Server_List_Ping#Client_to_server
+func createLegacyRequestPacket(hostname string, port uint16) []byte {
+	pingHostData := []byte{legacyProtocolVersion}
+	pingHostData = append(pingHostData, legacyStringToBytes(hostname)...)
+	portInBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(portInBytes, uint32(port))
+	pingHostData = append(pingHostData, portInBytes...)
+
+	requestPacket := append([]byte{}, legacyRequestPacket...)
+	requestPacket = append(requestPacket, legacyStringToBytes(legacyPingHostChannel)...)
+	dataLength := make([]byte, 2)
+	binary.BigEndian.PutUint16(dataLength, uint16(len(pingHostData)))
+	requestPacket = append(requestPacket, dataLength...)
+	requestPacket = append(requestPacket, pingHostData...)
+
+	return requestPacket
+}
+
+// legacyStringToBytes encodes s as UTF-16BE and prepends it with a short containing its length in characters.
+func legacyStringToBytes(s string) []byte {
+	characters := utf16.Encode([]rune(s))
+
+	stringInBytes := make([]byte, 2+len(characters)*2)
+	binary.BigEndian.PutUint16(stringInBytes, uint16(len(characters)))
+	for i, character := range characters {
+		binary.BigEndian.PutUint16(stringInBytes[2+i*2:], character)
+	}
+
+	return stringInBytes
+}
+
+// readLegacyStatusResponse receives the full legacy status response from the server, including the kick packet ID and the length that prepend it.
+//
+// Reading continues until the length declared by the server is received, as the response can arrive in several chunks.
+// The latency is measured by timer, which was started when the request was sent.
+func readLegacyStatusResponse(con net.Conn, timeout time.Duration, maxResponseSize int, timer latencyTimer) ([]byte, time.Duration, error) {
+	header := make([]byte, 3)
 	setDeadline(&con, timeout)
 
-	startTime := time.Now()
-	bytesRead, err := con.Read(response)
+	_, err := io.ReadFull(con, header)
 	if err != nil {
 		return nil, -1, err
 	}
-	latency := time.Since(startTime)
 
-	response = response[0:bytesRead]
+	// The response is UTF-16BE encoded and the short contains the number of characters, so each character takes 2 bytes.
+	responseSize := int(binary.BigEndian.Uint16(header[1:])) * 2
 
-	return response, latency, nil
+	// Refuse to read responses declared larger than the maximum before receiving any of it.
+	if responseSize > maxResponseSize {
+		return nil, -1, ErrResponseTooLarge
+	}
+
+	response := make([]byte, responseSize)
+	setDeadline(&con, timeout)
+
+	_, err = io.ReadFull(con, response)
+	if err != nil {
+		return nil, -1, err
+	}
+	latency := timer.elapsed()
+
+	return append(header, response...), latency, nil
 }
 
 // packageLegacyStatusResponse parses and packages the response into statusLegacy.
@@ -122,12 +224,16 @@ func packageLegacyStatusResponse(serverIP string, port uint16, latency time.Dura
 	statusLegacy.Port = port
 	statusLegacy.Latency = latency
 
-	responseList, err := parseLegacyStatusResponse(response)
+	responseList, isPre14, err := parseLegacyStatusResponse(response)
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
 
-	err = packageLegacyStatusValues(responseList, &statusLegacy)
+	if isPre14 {
+		err = packagePre14LegacyStatusValues(responseList, &statusLegacy)
+	} else {
+		err = packageLegacyStatusValues(responseList, &statusLegacy)
+	}
 	if err != nil {
 		return StatusLegacyResponse{}, err
 	}
@@ -135,40 +241,44 @@ func packageLegacyStatusResponse(serverIP string, port uint16, latency time.Dura
 	return statusLegacy, nil
 }
 
-// parseLegacyStatusResponse parses the doubly null-terminated byte string values into a []string.
-func parseLegacyStatusResponse(response []byte) ([]string, error) {
+// parseLegacyStatusResponse decodes the UTF-16BE encoded response and splits it into its values.
+//
+// Responses beginning with "§1" are sent by servers running 1.4 or newer and are split on nulls.
+// Older servers answer with the beta layout split on "§", in which case isPre14 is true.
+// https://wiki.vg/Server_List_Ping#Server_to_client
+func parseLegacyStatusResponse(response []byte) ([]string, bool, error) {
 	if len(response) < 10 {
-		return nil, ErrShortQueryResponse
-	}
-
-	// Remove the bytes that prepend the response.
-	response = response[9:]
-
-	responseList := []string{}
-	currentValue := []byte{}
-
-	// appendValue is set to true when the read byte is 0. If the subsequent byte is 0, the value is appended and appendValue is set to false.
-	var appendValue bool
-	for _, currentByte := range response {
-		if currentByte == 0 {
-			// Last byte was 0, so append this value.
-			if appendValue {
-				responseList = append(responseList, string(currentValue))
-				currentValue = []byte{}
-				appendValue = false
-			} else {
-				appendValue = true
-			}
-		} else {
-			currentValue = append(currentValue, currentByte)
-			appendValue = false
-		}
+		return nil, false, ErrShortStatusLegacyResponse
 	}
 
-	// Append the final value that wasn't terminated with two null characters.
-	responseList = append(responseList, string(currentValue))
+	// Remove the kick packet ID and the length that prepend the response.
+	decodedResponse := decodeUTF16BE(response[3:])
+
+	if !strings.HasPrefix(decodedResponse, legacyResponsePrefix) {
+		return strings.Split(decodedResponse, betaValueSplit), true, nil
+	}
 
-	return responseList, nil
+	// Remove the "§1" that begins the response.
+	return strings.Split(strings.TrimPrefix(decodedResponse, legacyResponsePrefix), legacyValueSplit), false, nil
+}
+
+// packagePre14LegacyStatusValues packages the values sent in the beta layout by servers older than 1.4 into statusLegacy.
+//
+// These servers don't send their version, so Version.Protocol is -1 and Version.Name is empty.
+func packagePre14LegacyStatusValues(responseList []string, statusLegacy *StatusLegacyResponse) error {
+	statusBeta := StatusBetaResponse{}
+
+	err := packageBetaStatusResponseValues(responseList, &statusBeta)
+	if err != nil {
+		return err
+	}
+
+	statusLegacy.Version.Protocol = -1
+	statusLegacy.Description = statusBeta.Description
+	statusLegacy.Players.Online = statusBeta.Players.Online
+	statusLegacy.Players.Max = statusBeta.Players.Max
+
+	return nil
 }
 
 // packageLegacyStatusValues takes responseList and parses and packages the values into statusLegacy.
@@ -208,8 +318,8 @@ func packageLegacyStatusValues(responseList []string, statusLegacy *StatusLegacy
 const (
 	// betaRequestPacket is the packet sent to elicit a beta status response from the server.
 	betaRequestPacket byte = 0xFE
-	// betaValueSplit contains the value that each value is separated with in the response byte string.
-	betaValueSplit byte = 0xA7
+	// betaValueSplit contains the character that each value is separated with in the decoded response.
+	betaValueSplit string = "§"
 )
 
 // Errors.
@@ -226,6 +336,17 @@ type StatusBetaResponse struct {
 	// Port contains the server's port used for communication.
 	Port uint16
 
+	// RemoteAddr contains the address the connection actually landed on, including the port.
+	//
+	// It is excluded from JSON like the RemoteAddr of StatusResponse.
+	RemoteAddr net.Addr `json:"-"`
+
+	// BytesSent contains the total number of bytes sent to the server during the request.
+	BytesSent int `json:"-"`
+
+	// BytesReceived contains the total number of bytes received from the server during the request.
+	BytesReceived int `json:"-"`
+
 	// Latency contains the duration of time waited for the response.
 	Latency time.Duration
 
@@ -247,32 +368,62 @@ type StatusBetaResponse struct {
 //
 // If a valid response is received, a StatusBetaResponse is returned.
 // https://wiki.vg/Server_List_Ping#Beta_1.8_to_1.3
-func StatusBeta(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration) (StatusBetaResponse, error) {
-	serverAndPort := fmt.Sprintf("%s:%d", server, port)
+func StatusBeta(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, opts ...Option) (StatusBetaResponse, error) {
+	return statusBetaDial(server, port, initialConnectionTimeout, ioTimeout, newOptions(opts))
+}
 
-	con, err := net.DialTimeout("tcp", serverAndPort, initialConnectionTimeout)
+// statusBetaDial dials the server and performs the beta status request.
+func statusBetaDial(server string, port uint16, initialConnectionTimeout time.Duration, ioTimeout time.Duration, options options) (StatusBetaResponse, error) {
+	con, err := dial("tcp", server, port, initialConnectionTimeout, ProtocolStatusBeta, options)
 	if err != nil {
 		return StatusBetaResponse{}, err
 	}
-	// If the connection closes normally, this line will run but not do anything.
 	defer resetConnection(con)
 
-	// Split the string "IP:PORT" by : to get the IP of the remote host.
-	serverIP := strings.Split(con.RemoteAddr().String(), ":")[0]
+	con, stats := options.wrapConn(con, ProtocolStatusBeta)
+	startTime := time.Now()
 
-	err = initiateRequest(con, ioTimeout, []byte{betaRequestPacket})
+	statusBeta, err := performStatusBetaRequest(con, port, ioTimeout, options)
+	err = classifyNetworkError(err)
+	options.hookOutcome(ProtocolStatusBeta, stats, time.Since(startTime), err)
+	options.recordStats(stats)
+	if err == nil {
+		statusBeta.BytesSent = stats.BytesSent
+		statusBeta.BytesReceived = stats.BytesReceived
+	}
+
+	return statusBeta, err
+}
+
+// performStatusBetaRequest performs the beta status request over con.
+func performStatusBetaRequest(con net.Conn, port uint16, ioTimeout time.Duration, options options) (StatusBetaResponse, error) {
+	serverIP := remoteIP(con)
+
+	err := options.writeProxyHeader(con, ioTimeout)
 	if err != nil {
 		return StatusBetaResponse{}, err
 	}
 
-	response, latency, err := readBetaStatusResponse(con, ioTimeout)
+	timer := options.startLatencyTimer()
+	err = initiateRequest(con, ioTimeout, []byte{betaRequestPacket})
 	if err != nil {
 		return StatusBetaResponse{}, err
 	}
 
-	con.Close()
+	response, latency, err := readBetaStatusResponse(con, ioTimeout, options.maxResponseSize, options.readBufferSizeOr(betaReadBufferSize), timer)
+	if err != nil {
+		return StatusBetaResponse{}, err
+	}
+	options.trace("beta status response: %d bytes", len(response))
 
 	statusBeta, err := packageBetaStatusResponse(serverIP, port, latency, response)
+	options.traceOutcome("beta status", err)
+	if err != nil {
+		return StatusBetaResponse{}, err
+	}
+	statusBeta.RemoteAddr = con.RemoteAddr()
+
+	err = options.validatePlayerCounts(statusBeta.Players.Online, statusBeta.Players.Max)
 	if err != nil {
 		return StatusBetaResponse{}, err
 	}
@@ -281,19 +432,25 @@ func StatusBeta(server string, port uint16, initialConnectionTimeout time.Durati
 }
 
 // readBetaStatusResponse receives the full beta status response from the server.
-func readBetaStatusResponse(con net.Conn, timeout time.Duration) ([]byte, time.Duration, error) {
+//
+// The latency is measured by timer, which was started when the request was sent.
+func readBetaStatusResponse(con net.Conn, timeout time.Duration, maxResponseSize int, bufferSize int, timer latencyTimer) ([]byte, time.Duration, error) {
 	responseSize, err := readBetaStatusResponseSize(con, timeout)
 	if err != nil {
 		return nil, -1, err
 	}
 
+	// Refuse to read responses declared larger than the maximum before receiving any of it.
+	if responseSize > maxResponseSize {
+		return nil, -1, ErrResponseTooLarge
+	}
+
 	response := []byte{}
+	recvBuffer := make([]byte, bufferSize)
 
 	// Keep receiving bytes until the full message is received.
 	setDeadline(&con, timeout)
-	startTime := time.Now()
 	for len(response) < responseSize {
-		recvBuffer := make([]byte, 32)
 		bytesRead, err := con.Read(recvBuffer)
 
 		if err != nil {
@@ -302,7 +459,7 @@ func readBetaStatusResponse(con net.Conn, timeout time.Duration) ([]byte, time.D
 
 		response = append(response, recvBuffer[0:bytesRead]...)
 	}
-	latency := time.Since(startTime)
+	latency := timer.elapsed()
 
 	return response, latency, nil
 }
@@ -310,8 +467,9 @@ func readBetaStatusResponse(con net.Conn, timeout time.Duration) ([]byte, time.D
 // readBetaStatusResponseSize reads and parses the short that prepends the server's response which contains the length of the response.
 func readBetaStatusResponseSize(con net.Conn, timeout time.Duration) (int, error) {
 	response := make([]byte, 3)
+	setDeadline(&con, timeout)
 
-	_, err := con.Read(response)
+	_, err := io.ReadFull(con, response)
 	if err != nil {
 		return -1, err
 	}
@@ -319,7 +477,7 @@ func readBetaStatusResponseSize(con net.Conn, timeout time.Duration) (int, error
 	// Remove the kick packet from the front.
 	response = response[1:]
 
-	// For unknown reasons (most likely due to encoding), the response size must be multiplied by 2 to contain the actual response length.
+	// The response is UTF-16BE encoded and the short contains the number of characters, so each character takes 2 bytes.
 	responseSize := int(binary.BigEndian.Uint16(response)) * 2
 
 	return responseSize, nil
@@ -342,19 +500,9 @@ func packageBetaStatusResponse(serverIP string, port uint16, latency time.Durati
 	return statusBeta, nil
 }
 
-// parseBetaStatusResponse parses the 0xA7 terminated byte string into a []string.
+// parseBetaStatusResponse decodes the UTF-16BE response and splits its § separated values into a []string.
 func parseBetaStatusResponse(response []byte) []string {
-	// Split all the 0xA7 separated values.
-	valueSplit := bytes.Split(response, []byte{betaValueSplit})
-
-	// Remove all 0x00 null characters from the values.
-	responseList := []string{}
-	for _, value := range valueSplit {
-		cleanedValue := string(bytes.ReplaceAll(value, []byte{0x00}, []byte{}))
-		responseList = append(responseList, cleanedValue)
-	}
-
-	return responseList
+	return strings.Split(decodeUTF16BE(response), betaValueSplit)
 }
 
 // packageBetaStatusResponseValues takes responseList and parses and packages the values into statusBeta.
@@ -380,4 +528,4 @@ func packageBetaStatusResponseValues(responseList []string, statusBeta *StatusBe
 	statusBeta.Players.Max = playersMax
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package mcstatusgo
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+// legacyResponse encodes s as a kick packet containing a legacy status response.
+func legacyResponse(s string) []byte {
+	characters := utf16.Encode([]rune(s))
+
+	response := []byte{0xFF, 0x00, 0x00}
+	binary.BigEndian.PutUint16(response[1:], uint16(len(characters)))
+	for _, character := range characters {
+		response = append(response, byte(character>>8), byte(character))
+	}
+
+	return response
+}
+
+func TestParseStatusLegacyResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     StatusLegacyResponse
+	}{
+		{
+			name:     "1.4 and newer",
+			response: "§1\x00127\x001.6.4\x00A Minecraft Server\x003\x0020",
+			want:     legacyStatus(127, "1.6.4", "A Minecraft Server", 3, 20),
+		},
+		{
+			name:     "older than 1.4",
+			response: "A Minecraft Server§3§20",
+			want:     legacyStatus(-1, "", "A Minecraft Server", 3, 20),
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ParseStatusLegacyResponse(legacyResponse(test.response))
+		if err != nil {
+			t.Errorf("%s: ParseStatusLegacyResponse: %v", test.name, err)
+			continue
+		}
+		if got.Version != test.want.Version || got.Description != test.want.Description || got.Players != test.want.Players {
+			t.Errorf("%s: ParseStatusLegacyResponse() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseStatusLegacyResponseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		response []byte
+		want     error
+	}{
+		{"short", []byte{0xFF, 0x00, 0x01, 0x00, 0x41}, ErrShortStatusLegacyResponse},
+		{"missing values", legacyResponse("§1\x00127\x001.6.4\x00motd"), ErrStatusLegacyMissingInformation},
+		{"pre 1.4 missing values", legacyResponse("A Minecraft Server§3"), ErrStatusBetaMissingInformation},
+	}
+
+	for _, test := range tests {
+		_, err := ParseStatusLegacyResponse(test.response)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: ParseStatusLegacyResponse() error = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// legacyStatus creates the expected StatusLegacyResponse.
+func legacyStatus(protocol int, version string, description string, online int, max int) StatusLegacyResponse {
+	status := StatusLegacyResponse{}
+	status.Version.Protocol = protocol
+	status.Version.Name = version
+	status.Description = description
+	status.Players.Online = online
+	status.Players.Max = max
+
+	return status
+}
+
+func TestParseStatusLegacyResponseUTF16(t *testing.T) {
+	// The emoji is outside the Basic Multilingual Plane, so it is sent as a surrogate pair.
+	description := "Café Ünïcode 🎮 服务器"
+	if len(utf16.Encode([]rune(description))) == len([]rune(description)) {
+		t.Fatal("description doesn't contain a surrogate pair")
+	}
+
+	for _, response := range []string{
+		"§1\x00127\x001.6.4\x00" + description + "\x003\x0020",
+		description + "§3§20",
+	} {
+		got, err := ParseStatusLegacyResponse(legacyResponse(response))
+		if err != nil {
+			t.Fatalf("ParseStatusLegacyResponse: %v", err)
+		}
+		if got.Description != description {
+			t.Errorf("Description = %q, want %q", got.Description, description)
+		}
+	}
+
+	beta, err := ParseStatusBetaResponse(legacyResponse(description + "§3§20"))
+	if err != nil {
+		t.Fatalf("ParseStatusBetaResponse: %v", err)
+	}
+	if beta.Description != description {
+		t.Errorf("beta Description = %q, want %q", beta.Description, description)
+	}
+}
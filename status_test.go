@@ -0,0 +1,442 @@
+package mcstatusgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	// testTimeout is the connection and io timeout used by requests to the fake servers.
+	testTimeout time.Duration = 2 * time.Second
+	// testDocument is a minimal valid status response document.
+	testDocument string = `{"version":{"name":"1.20.4","protocol":765},"players":{"max":20,"online":3},"description":{"text":"A Minecraft Server"}}`
+)
+
+// startFakeServer listens on a local TCP port and calls handle with each accepted connection, returning the port.
+func startFakeServer(t *testing.T, handle func(con net.Conn)) uint16 {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			con, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer con.Close()
+				con.SetDeadline(time.Now().Add(testTimeout))
+				handle(con)
+			}()
+		}
+	}()
+
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// serveStatus returns a handler answering the status request with document, a PROXY protocol header being skipped if sent first.
+//
+// The ping is echoed after waiting pongDelay.
+func serveStatus(document string, pongDelay time.Duration) func(con net.Conn) {
+	return func(con net.Conn) {
+		reader := bufio.NewReader(con)
+		if skipTestProxyHeader(reader) != nil {
+			return
+		}
+
+		// The handshake and the status request.
+		for i := 0; i < 2; i++ {
+			if _, err := readTestPacket(reader); err != nil {
+				return
+			}
+		}
+
+		response := statusResponsePacket(document)
+		con.Write(WriteVarInt(len(response)))
+		con.Write(response)
+
+		ping, err := readTestPacket(reader)
+		if err != nil {
+			return
+		}
+		time.Sleep(pongDelay)
+		con.Write(append(WriteVarInt(len(ping)), ping...))
+	}
+}
+
+// statusResponsePacket wraps document in a status response packet, without the packet length.
+func statusResponsePacket(document string) []byte {
+	response := append([]byte{0x00}, WriteVarInt(len(document))...)
+
+	return append(response, document...)
+}
+
+// parseTestDocument packages document as if it was received in a status response.
+func parseTestDocument(t *testing.T, document string) StatusResponse {
+	t.Helper()
+
+	status, err := packageStatusResponse("", 0, -1, statusResponsePacket(document), DefaultPlayerSampleLimit)
+	if err != nil {
+		t.Fatalf("packageStatusResponse: %v", err)
+	}
+
+	return status
+}
+
+// readTestData reads a file from the testdata directory.
+func readTestData(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read testdata: %v", err)
+	}
+
+	return string(data)
+}
+
+// skipTestProxyHeader reads past the PROXY protocol header at the start of reader, if there is one.
+func skipTestProxyHeader(reader *bufio.Reader) error {
+	start, err := reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(start, proxyV1Prefix):
+		_, err = reader.ReadString('\n')
+	case bytes.Equal(start, proxyV2Signature):
+		header := make([]byte, len(proxyV2Signature)+4)
+		if _, err = io.ReadFull(reader, header); err != nil {
+			return err
+		}
+		_, err = reader.Discard(int(binary.BigEndian.Uint16(header[len(header)-2:])))
+	}
+
+	return err
+}
+
+// readTestPacket reads a packet prefixed with its length.
+func readTestPacket(reader *bufio.Reader) ([]byte, error) {
+	length, err := readVarIntFrom(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := make([]byte, length)
+	_, err = io.ReadFull(reader, packet)
+
+	return packet, err
+}
+
+func TestStatus(t *testing.T) {
+	port := startFakeServer(t, serveStatus(testDocument, 0))
+
+	status, err := Status("127.0.0.1", port, testTimeout, testTimeout)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	if status.Version.Name != "1.20.4" || status.Version.Protocol != 765 {
+		t.Errorf("version = %+v, want 1.20.4 (765)", status.Version)
+	}
+	if status.Players.Online != 3 || status.Players.Max != 20 {
+		t.Errorf("players = %d/%d, want 3/20", status.Players.Online, status.Players.Max)
+	}
+	if status.PlainDescription() != "A Minecraft Server" {
+		t.Errorf("PlainDescription() = %q, want %q", status.PlainDescription(), "A Minecraft Server")
+	}
+}
+
+func TestStatusRaw(t *testing.T) {
+	// Vendor fields that StatusResponse doesn't model must be kept in the raw document.
+	document := `{"version":{"name":"1.20.4","protocol":765},"players":{"max":20,"online":3},"description":"A Minecraft Server","vendor":{"region":"eu"}}`
+	port := startFakeServer(t, serveStatus(document, 0))
+
+	raw, latency, err := StatusRaw("127.0.0.1", port, testTimeout, testTimeout)
+	if err != nil {
+		t.Fatalf("StatusRaw: %v", err)
+	}
+	if string(raw) != document {
+		t.Errorf("StatusRaw() = %s, want %s", raw, document)
+	}
+	if latency < 0 {
+		t.Errorf("latency = %v, want the measured latency", latency)
+	}
+}
+
+func TestStatusPingFailure(t *testing.T) {
+	// The server closes the connection as soon as the status response is sent, before the pong.
+	port := startFakeServer(t, func(con net.Conn) {
+		reader := bufio.NewReader(con)
+		for i := 0; i < 2; i++ {
+			if _, err := readTestPacket(reader); err != nil {
+				return
+			}
+		}
+
+		response := statusResponsePacket(testDocument)
+		con.Write(append(WriteVarInt(len(response)), response...))
+	})
+
+	status, err := Status("127.0.0.1", port, testTimeout, testTimeout)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Latency != -1 || status.PingErr == nil {
+		t.Errorf("Latency = %v, PingErr = %v, want -1 with the ping failure", status.Latency, status.PingErr)
+	}
+	if status.Players.Online != 3 || status.Players.Max != 20 {
+		t.Errorf("players = %d/%d, want the status to be kept with 3/20", status.Players.Online, status.Players.Max)
+	}
+}
+
+func TestStatusServerClosedConnection(t *testing.T) {
+	// The server reads the handshake and status request, then closes the connection without responding, like a server in maintenance mode.
+	port := startFakeServer(t, func(con net.Conn) {
+		reader := bufio.NewReader(con)
+		for i := 0; i < 2; i++ {
+			if _, err := readTestPacket(reader); err != nil {
+				return
+			}
+		}
+	})
+
+	_, err := Status("127.0.0.1", port, testTimeout, testTimeout)
+	if !errors.Is(err, ErrServerClosedConnection) || !errors.Is(err, io.EOF) {
+		t.Errorf("Status() error = %v, want an error matching ErrServerClosedConnection and io.EOF", err)
+	}
+}
+
+func TestStatusResponseTooLarge(t *testing.T) {
+	// The server declares a response of nearly the largest size allowed by the protocol and never sends it, so reading any of it would block until the io timeout.
+	// The largest size itself isn't declared, as its varint starts with the kick packet ID.
+	declaredSize := 2000000
+	header := WriteVarInt(declaredSize)
+	hang := make(chan struct{})
+	defer close(hang)
+	port := startFakeServer(t, func(con net.Conn) {
+		reader := bufio.NewReader(con)
+		for i := 0; i < 2; i++ {
+			if _, err := readTestPacket(reader); err != nil {
+				return
+			}
+		}
+
+		con.Write(header)
+		<-hang
+	})
+
+	stats := &Stats{}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+	_, err := Status("127.0.0.1", port, testTimeout, 10*time.Second, WithMaxResponseSize(1024), WithStats(stats))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Status() error = %v, want ErrResponseTooLarge", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated >= uint64(declaredSize) {
+		t.Errorf("Status() allocated %d bytes, want the buffer for the declared size never to be allocated", allocated)
+	}
+	if elapsed := time.Since(startTime); elapsed > 5*time.Second {
+		t.Errorf("Status() took %v, want the declared size to be refused without waiting for the response", elapsed)
+	}
+	if stats.BytesReceived != len(header) {
+		t.Errorf("BytesReceived = %d, want only the %d byte size header to be read", stats.BytesReceived, len(header))
+	}
+}
+
+func TestDescriptionShapes(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		wantText  string
+		wantExtra int
+	}{
+		// Vanilla servers send the description as a plain string alongside the favicon.
+		{"description_string.json", "A Minecraft Server", 0},
+		// Paper servers send a chat component with styled siblings.
+		{"description_component.json", "", 3},
+	}
+
+	for _, test := range tests {
+		status := parseTestDocument(t, readTestData(t, test.fixture))
+
+		// Both shapes are normalized into a chat component object.
+		var component struct {
+			Text  *string
+			Extra []json.RawMessage
+		}
+		err := json.Unmarshal([]byte(status.Description), &component)
+		if err != nil {
+			t.Errorf("%s: Description = %q, want a chat component object: %v", test.fixture, status.Description, err)
+			continue
+		}
+		if component.Text == nil || *component.Text != test.wantText || len(component.Extra) != test.wantExtra {
+			t.Errorf("%s: Description = %q, want text %q with %d siblings", test.fixture, status.Description, test.wantText, test.wantExtra)
+		}
+	}
+}
+
+// benchmarkPacket keeps the packets built by the benchmarks from being optimized away.
+var benchmarkPacket []byte
+
+func BenchmarkStatusHandshake(b *testing.B) {
+	b.Run("Create", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkPacket = createStatusHandshakePacket(protocolVersion, "mc.example.com", 25565)
+		}
+	})
+
+	b.Run("Append", func(b *testing.B) {
+		b.ReportAllocs()
+		packet := make([]byte, 0, statusHandshakePacketSize(protocolVersion, "mc.example.com"))
+		for i := 0; i < b.N; i++ {
+			packet = appendStatusHandshakePacket(packet[:0], protocolVersion, "mc.example.com", 25565)
+		}
+		benchmarkPacket = packet
+	})
+}
+
+func TestPlayerCountsAsNumbersOrStrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		players string
+	}{
+		{"numbers", `{"max":20,"online":3}`},
+		{"strings", `{"max":"20","online":"3"}`},
+		{"padded strings", `{"max":" 20 ","online":"3"}`},
+		{"mixed", `{"max":20,"online":"3"}`},
+	}
+
+	for _, test := range tests {
+		status := parseTestDocument(t, `{"version":{"name":"1.20.4","protocol":765},"players":`+test.players+`,"description":""}`)
+		if status.Players.Online != 3 || status.Players.Max != 20 {
+			t.Errorf("%s: players = %d/%d, want 3/20", test.name, status.Players.Online, status.Players.Max)
+		}
+	}
+
+	_, err := packageStatusResponse("", 0, -1, statusResponsePacket(`{"version":{"name":"1.20.4","protocol":765},"players":{"max":"many","online":3},"description":""}`), DefaultPlayerSampleLimit)
+	if err == nil {
+		t.Error("packageStatusResponse() accepted a non-numeric player count")
+	}
+}
+
+func TestFormatStatusResponse(t *testing.T) {
+	document := `{"description":""}`
+
+	tests := []struct {
+		name     string
+		response []byte
+	}{
+		{"exact length", statusResponsePacket(document)},
+		{"padded", append(statusResponsePacket(document), 0x00, 0x00, 0x00)},
+		{"padded with garbage", append(statusResponsePacket(document), "trailing"...)},
+	}
+
+	for _, test := range tests {
+		got, err := formatStatusResponse(test.response)
+		if err != nil {
+			t.Errorf("%s: formatStatusResponse: %v", test.name, err)
+			continue
+		}
+		if string(got) != document {
+			t.Errorf("%s: formatStatusResponse() = %q, want %q", test.name, got, document)
+		}
+	}
+}
+
+func TestFormatStatusResponseInvalid(t *testing.T) {
+	truncated := statusResponsePacket(`{"description":""}`)
+	truncated = truncated[:len(truncated)-1]
+
+	tests := []struct {
+		name     string
+		response []byte
+		want     error
+	}{
+		{"shorter than declared", truncated, ErrInvalidSizeInfo},
+		{"too short", []byte{0x00, 0x01, '{'}, ErrShortStatusResponse},
+		{"truncated length", []byte{0x00, 0x80, 0x80, 0x80}, io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		_, err := formatStatusResponse(test.response)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: formatStatusResponse() error = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestRemoteAddrExcludedFromJSON(t *testing.T) {
+	remoteAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 25565}
+	responses := []interface{}{
+		StatusResponse{RemoteAddr: remoteAddr},
+		StatusLegacyResponse{RemoteAddr: remoteAddr},
+		StatusBetaResponse{RemoteAddr: remoteAddr},
+		BasicQueryResponse{RemoteAddr: remoteAddr},
+		FullQueryResponse{RemoteAddr: remoteAddr},
+	}
+
+	for _, response := range responses {
+		encoded, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("%T: json.Marshal: %v", response, err)
+		}
+		if bytes.Contains(encoded, []byte("RemoteAddr")) {
+			t.Errorf("%T encoded as %s, want the remote address excluded", response, encoded)
+		}
+	}
+}
+
+func TestStatusKickPacket(t *testing.T) {
+	// Reasons of 256 characters or more set the high byte of the reason length.
+	for _, reason := range []string{"Outdated client!", strings.Repeat("Outdated client! ", 20)} {
+		port := startFakeServer(t, func(con net.Conn) {
+			con.Read(make([]byte, 512))
+			con.Write(legacyResponse(reason))
+		})
+
+		_, err := Status("127.0.0.1", port, testTimeout, testTimeout)
+		var rejected ErrServerRejectedStatus
+		if !errors.As(err, &rejected) || rejected.Reason != reason {
+			t.Errorf("%d character reason: Status() error = %v, want ErrServerRejectedStatus with the reason", len(reason), err)
+		}
+	}
+}
+
+func TestParseStatusResponseStartingWithKickPacketID(t *testing.T) {
+	// A 252 byte document makes the response 255 bytes long, so its length varint starts with the kick packet ID.
+	document := testDocument[:len(testDocument)-1] + `,"padding":"` + strings.Repeat("a", 252-len(testDocument)-13) + `"}`
+	response := statusResponsePacket(document)
+	raw := append(WriteVarInt(len(response)), response...)
+	if raw[0] != kickPacketID {
+		t.Fatalf("raw response starts with %#x, want %#x", raw[0], kickPacketID)
+	}
+
+	status, err := ParseStatusResponse(raw)
+	if err != nil {
+		t.Fatalf("ParseStatusResponse: %v", err)
+	}
+	if status.Players.Online != 3 || status.Players.Max != 20 {
+		t.Errorf("players = %d/%d, want 3/20", status.Players.Online, status.Players.Max)
+	}
+}
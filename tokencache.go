@@ -0,0 +1,99 @@
+package mcstatusgo
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultChallengeTokenTTL is the duration challenge tokens are cached for, just below the 30 seconds servers keep them valid for.
+	DefaultChallengeTokenTTL time.Duration = 25 * time.Second
+)
+
+// ChallengeTokenCache caches the challenge tokens received from query handshakes so repeated query requests can skip the handshake.
+//
+// Servers tie each challenge token to the client's address, so a cached token is only reused by connections with the same local and remote address.
+// With the dialing query functions this requires binding a fixed local port using WithLocalAddr, while the Conn query functions reuse the caller's connection.
+// A cached token the server no longer accepts is discarded and the handshake is performed again automatically.
+// A ChallengeTokenCache is safe for concurrent use.
+// https://wiki.vg/Query#Handshake
+type ChallengeTokenCache struct {
+	ttl time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]challengeTokenEntry
+}
+
+// challengeTokenEntry contains a cached challenge token along with the sessionID it was received for.
+type challengeTokenEntry struct {
+	sessionID      []byte
+	challengeToken []byte
+	expiry         time.Time
+}
+
+// NewChallengeTokenCache creates a ChallengeTokenCache that keeps challenge tokens for ttl.
+//
+// If ttl is 0 or less, DefaultChallengeTokenTTL is used.
+func NewChallengeTokenCache(ttl time.Duration) *ChallengeTokenCache {
+	if ttl <= 0 {
+		ttl = DefaultChallengeTokenTTL
+	}
+
+	return &ChallengeTokenCache{
+		ttl:     ttl,
+		entries: make(map[string]challengeTokenEntry),
+	}
+}
+
+// get returns the cached sessionID and challenge token for con if there is an unexpired one.
+func (c *ChallengeTokenCache) get(con net.Conn) ([]byte, []byte, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := challengeTokenKey(con)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if time.Now().After(entry.expiry) {
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+
+	return entry.sessionID, entry.challengeToken, true
+}
+
+// put caches the sessionID and challenge token received over con.
+func (c *ChallengeTokenCache) put(con net.Conn, sessionID []byte, challengeToken []byte) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[challengeTokenKey(con)] = challengeTokenEntry{sessionID, challengeToken, time.Now().Add(c.ttl)}
+}
+
+// invalidate discards the cached challenge token for con.
+func (c *ChallengeTokenCache) invalidate(con net.Conn) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, challengeTokenKey(con))
+}
+
+// challengeTokenKey identifies the challenge token of con by its local and remote address.
+func challengeTokenKey(con net.Conn) string {
+	return con.LocalAddr().String() + "->" + con.RemoteAddr().String()
+}
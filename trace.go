@@ -0,0 +1,52 @@
+package mcstatusgo
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// traceConn writes a hex dump of the traffic of the net.Conn it wraps to w.
+type traceConn struct {
+	net.Conn
+	w io.Writer
+}
+
+// Read reads from the wrapped net.Conn and traces the received bytes.
+func (c traceConn) Read(b []byte) (int, error) {
+	bytesRead, err := c.Conn.Read(b)
+	if bytesRead > 0 {
+		fmt.Fprintf(c.w, "read %d bytes: % x\n", bytesRead, b[:bytesRead])
+	}
+	if err != nil {
+		fmt.Fprintf(c.w, "read error: %v\n", err)
+	}
+
+	return bytesRead, err
+}
+
+// Write writes to the wrapped net.Conn and traces the sent bytes.
+func (c traceConn) Write(b []byte) (int, error) {
+	bytesWritten, err := c.Conn.Write(b)
+	fmt.Fprintf(c.w, "wrote %d bytes: % x\n", bytesWritten, b[:bytesWritten])
+	if err != nil {
+		fmt.Fprintf(c.w, "write error: %v\n", err)
+	}
+
+	return bytesWritten, err
+}
+
+// lockedWriter serializes the writes to the io.Writer it wraps, which is shared by the traces of concurrent requests.
+type lockedWriter struct {
+	w     io.Writer
+	mutex *sync.Mutex
+}
+
+// Write writes b to the wrapped io.Writer while holding the mutex.
+func (l lockedWriter) Write(b []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.w.Write(b)
+}
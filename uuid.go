@@ -0,0 +1,82 @@
+package mcstatusgo
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Errors.
+var (
+	// ErrInvalidUUID is returned when a UUID string is not 32 hexadecimal digits, optionally separated by dashes in the 8-4-4-4-12 format.
+	ErrInvalidUUID error = errors.New("invalid uuid: uuid is not in a valid format")
+)
+
+// SamplePlayer contains a player from the player sample with their parsed UUID.
+type SamplePlayer struct {
+	// Name contains the username of the player.
+	Name string
+
+	// UUID contains the 128-bit value of the player's UUID.
+	UUID [16]byte
+
+	// Err contains the error encountered while parsing the player's UUID, if any.
+	Err error
+}
+
+// SamplePlayers parses the UUID of each player in the player sample.
+//
+// A malformed UUID doesn't stop the parsing of the rest of the sample and is instead reported in the Err field of its entry.
+func (s StatusResponse) SamplePlayers() []SamplePlayer {
+	players := []SamplePlayer{}
+
+	for _, player := range s.Players.Sample {
+		uuid, err := ParseUUID(player["id"])
+		players = append(players, SamplePlayer{Name: player["name"], UUID: uuid, Err: err})
+	}
+
+	return players
+}
+
+// ParseUUID parses a UUID string into its 128-bit equivalent.
+//
+// Both the dashed (8-4-4-4-12) and undashed forms are accepted.
+// The all-zero UUID sent by some offline-mode servers is considered valid.
+func ParseUUID(id string) ([16]byte, error) {
+	var uuid [16]byte
+
+	// Check that the dashes are in the correct positions before removing them.
+	if len(id) == 36 {
+		for _, position := range []int{8, 13, 18, 23} {
+			if id[position] != '-' {
+				return [16]byte{}, ErrInvalidUUID
+			}
+		}
+		id = strings.ReplaceAll(id, "-", "")
+	}
+
+	if len(id) != 32 {
+		return [16]byte{}, ErrInvalidUUID
+	}
+
+	_, err := hex.Decode(uuid[:], []byte(id))
+	if err != nil {
+		return [16]byte{}, ErrInvalidUUID
+	}
+
+	return uuid, nil
+}
+
+// sampleBanners returns the entries of sample with an all-zero or malformed UUID, which don't identify real players.
+func sampleBanners(sample []map[string]string) []map[string]string {
+	banners := []map[string]string{}
+
+	for _, player := range sample {
+		uuid, err := ParseUUID(player["id"])
+		if err != nil || uuid == [16]byte{} {
+			banners = append(banners, player)
+		}
+	}
+
+	return banners
+}
@@ -0,0 +1,93 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUUID(t *testing.T) {
+	notch := [16]byte{0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x47, 0x26, 0xa5, 0xbe, 0xfc, 0xa9, 0x0e, 0x38, 0xaa, 0xf5}
+
+	tests := []struct {
+		id   string
+		want [16]byte
+	}{
+		{"069a79f4-44e9-4726-a5be-fca90e38aaf5", notch},
+		{"069a79f444e94726a5befca90e38aaf5", notch},
+		{"069A79F4-44E9-4726-A5BE-FCA90E38AAF5", notch},
+		// The all-zero UUID sent by some offline-mode servers is valid.
+		{"00000000-0000-0000-0000-000000000000", [16]byte{}},
+		{"00000000000000000000000000000000", [16]byte{}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseUUID(test.id)
+		if err != nil {
+			t.Errorf("ParseUUID(%q): %v", test.id, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseUUID(%q) = %x, want %x", test.id, got, test.want)
+		}
+	}
+}
+
+func TestParseUUIDInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		// Dashes out of the 8-4-4-4-12 positions.
+		"069a79f444-e9-4726-a5be-fca90e38aaf5",
+		"069a79f4-44e94-726-a5be-fca90e38aaf5",
+		// A fifth dash in place of a digit.
+		"069a79f4-44e9-4726-a5be-fca90e38aa-5",
+		// Dashes in an undashed-length string.
+		"069a79f4-44e9-4726-a5be-fca90e38",
+		// Non-hexadecimal digits.
+		"069a79f4-44e9-4726-a5be-fca90e38aazz",
+		"g69a79f444e94726a5befca90e38aaf5",
+		"069a79f444e94726a5befca90e38aaf",
+		"069a79f444e94726a5befca90e38aaf5a",
+	}
+
+	for _, id := range tests {
+		uuid, err := ParseUUID(id)
+		if !errors.Is(err, ErrInvalidUUID) {
+			t.Errorf("ParseUUID(%q) error = %v, want ErrInvalidUUID", id, err)
+		}
+		if uuid != [16]byte{} {
+			t.Errorf("ParseUUID(%q) = %x, want the zero value on error", id, uuid)
+		}
+	}
+}
+
+func TestSamplePlayers(t *testing.T) {
+	status := StatusResponse{}
+	status.Players.Sample = []map[string]string{
+		{"name": "Notch", "id": "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+		{"name": "§aWelcome!", "id": "not-a-uuid"},
+		{"name": "jeb_", "id": "853c80ef3c3749fdaa49938b674adae6"},
+	}
+
+	players := status.SamplePlayers()
+	if len(players) != len(status.Players.Sample) {
+		t.Fatalf("SamplePlayers() returned %d players, want %d", len(players), len(status.Players.Sample))
+	}
+
+	// Only the malformed entry reports an error, and the rest of the sample is still parsed.
+	for i, player := range players {
+		if player.Name != status.Players.Sample[i]["name"] {
+			t.Errorf("player %d: Name = %q, want %q", i, player.Name, status.Players.Sample[i]["name"])
+		}
+
+		wantErr := i == 1
+		if (player.Err != nil) != wantErr {
+			t.Errorf("player %d: Err = %v, want error %v", i, player.Err, wantErr)
+		}
+		if wantErr && !errors.Is(player.Err, ErrInvalidUUID) {
+			t.Errorf("player %d: Err = %v, want ErrInvalidUUID", i, player.Err)
+		}
+		if !wantErr && player.UUID == [16]byte{} {
+			t.Errorf("player %d: UUID wasn't parsed", i)
+		}
+	}
+}
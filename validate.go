@@ -0,0 +1,80 @@
+package mcstatusgo
+
+var (
+	// statusLegacyRequiredFields are the fields a legacy status response must contain, in the order they're checked.
+	statusLegacyRequiredFields []string = []string{"max players", "online players", "version name"}
+	// statusBetaRequiredFields are the fields a beta status response must contain, in the order they're checked.
+	statusBetaRequiredFields []string = []string{"max players", "online players"}
+	// basicQueryRequiredFields are the fields a basic query response must contain, in the order they're checked.
+	basicQueryRequiredFields []string = []string{"gametype", "map", "numplayers", "maxplayers"}
+)
+
+// Validate checks that s contains the information required of a status response, such as one reloaded from a cache, using the same required fields as a status request.
+//
+// ErrMissingInformation is returned if the description or version name is empty, or if a player count is negative, as in a stored record that lost its fields.
+// The version protocol can't be told apart from its zero value once packaged, so it is treated as present.
+func (s StatusResponse) Validate() error {
+	present := map[string]bool{
+		"description":      s.Description != "",
+		"max players":      s.Players.Max >= 0,
+		"online players":   s.Players.Online >= 0,
+		"version name":     s.Version.Name != "",
+		"version protocol": true,
+	}
+
+	return checkRequiredFields("status", statusRequiredFields, func(field string) bool { return present[field] })
+}
+
+// Validate checks that s contains the information required of a legacy status response, such as one reloaded from a cache.
+//
+// ErrMissingInformation is returned if a player count is negative, or if the version name is empty while the version protocol is set, as only responses from servers older than 1.4 leave it out.
+// The description can be empty on a real server, so it is treated as present.
+func (s StatusLegacyResponse) Validate() error {
+	present := map[string]bool{
+		"max players":    s.Players.Max >= 0,
+		"online players": s.Players.Online >= 0,
+		"version name":   s.Version.Name != "" || s.Version.Protocol == -1,
+	}
+
+	return checkRequiredFields("status legacy", statusLegacyRequiredFields, func(field string) bool { return present[field] })
+}
+
+// Validate checks that s contains the information required of a beta status response, such as one reloaded from a cache.
+//
+// ErrMissingInformation is returned if a player count is negative.
+// The description can be empty on a real server, so it is treated as present.
+func (s StatusBetaResponse) Validate() error {
+	present := map[string]bool{
+		"max players":    s.Players.Max >= 0,
+		"online players": s.Players.Online >= 0,
+	}
+
+	return checkRequiredFields("status beta", statusBetaRequiredFields, func(field string) bool { return present[field] })
+}
+
+// Validate checks that b contains the information required of a basic query response, such as one reloaded from a cache.
+//
+// ErrMissingInformation is returned if the game type or map is empty, or if a player count is negative, naming the full query key holding the same value.
+// The description can be empty on a real server, so it is treated as present.
+func (b BasicQueryResponse) Validate() error {
+	present := map[string]bool{
+		"gametype":   b.GameType != "",
+		"map":        b.MapName != "",
+		"numplayers": b.Players.Online >= 0,
+		"maxplayers": b.Players.Max >= 0,
+	}
+
+	return checkRequiredFields("query", basicQueryRequiredFields, func(field string) bool { return present[field] })
+}
+
+// Validate checks that f contains the information required of a full query response, such as one reloaded from a cache, using the same checks as a full query request.
+//
+// If RawKV is set, ErrMissingInformation is returned if it doesn't contain the keys required by default of a full query response.
+// Otherwise, the required fields can't be told apart from their zero value, so they are treated as present.
+func (f FullQueryResponse) Validate() error {
+	if f.RawKV == nil {
+		return nil
+	}
+
+	return validateQueryResponse(f.RawKV, defaultQueryRequiredKeys)
+}
@@ -0,0 +1,71 @@
+package mcstatusgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	status := parseTestDocument(t, testDocument)
+
+	// Zero and implausible player counts are both reported by servers, so neither is missing information.
+	implausible := status
+	implausible.Players.Online, implausible.Players.Max = 1000, 0
+
+	unpackaged := status
+	unpackaged.Description = ""
+
+	unnamed := status
+	unnamed.Version.Name = ""
+
+	negative := status
+	negative.Players.Max = -1
+
+	legacy := legacyStatus(127, "1.6.4", "A Minecraft Server", 3, 20)
+	preRelease := legacyStatus(-1, "", "", 0, 20)
+	unnamedLegacy := legacyStatus(127, "", "A Minecraft Server", 3, 20)
+
+	beta := StatusBetaResponse{}
+	beta.Players.Online, beta.Players.Max = 3, 20
+	negativeBeta := beta
+	negativeBeta.Players.Online = -1
+
+	basicQuery := BasicQueryResponse{GameType: "SMP", MapName: "world"}
+	basicQuery.Players.Online, basicQuery.Players.Max = 3, 20
+	unmapped := basicQuery
+	unmapped.MapName = ""
+
+	fullQuery := FullQueryResponse{RawKV: map[string]string{"hostname": "", "gametype": "SMP", "version": "", "map": "world", "numplayers": "0", "maxplayers": "0"}}
+	missingKey := FullQueryResponse{RawKV: map[string]string{"hostname": "A Minecraft Server", "gametype": "SMP"}}
+
+	tests := []struct {
+		name     string
+		response interface{ Validate() error }
+		want     error
+	}{
+		{"status", status, nil},
+		{"status with implausible players", implausible, nil},
+		{"status without description", unpackaged, ErrMissingInformation{"status", "description"}},
+		{"status without version name", unnamed, ErrMissingInformation{"status", "version name"}},
+		{"status with negative max players", negative, ErrMissingInformation{"status", "max players"}},
+		{"zero status", StatusResponse{}, ErrMissingInformation{"status", "description"}},
+		{"legacy", legacy, nil},
+		{"legacy older than 1.4", preRelease, nil},
+		{"legacy without version name", unnamedLegacy, ErrMissingInformation{"status legacy", "version name"}},
+		{"beta", beta, nil},
+		{"beta with negative online players", negativeBeta, ErrMissingInformation{"status beta", "online players"}},
+		{"basic query", basicQuery, nil},
+		{"basic query without map", unmapped, ErrMissingInformation{"query", "map"}},
+		{"zero basic query", BasicQueryResponse{}, ErrMissingInformation{"query", "gametype"}},
+		{"full query with empty values", fullQuery, nil},
+		{"full query without RawKV", FullQueryResponse{}, nil},
+		{"full query missing a key", missingKey, ErrMissingInformation{"query", "version"}},
+	}
+
+	for _, test := range tests {
+		err := test.response.Validate()
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: Validate() = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
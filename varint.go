@@ -0,0 +1,132 @@
+package mcstatusgo
+
+import (
+	"io"
+)
+
+const (
+	// maxVarIntSize is the maximum number of bytes a varint can be made up of.
+	maxVarIntSize int = 5
+)
+
+// WriteVarInt converts an int into its varint []byte equivalent.
+//
+// number is encoded as a 32-bit two's complement integer, so negative numbers always take 5 bytes.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+func WriteVarInt(number int) []byte {
+	return AppendVarInt(make([]byte, 0, varIntSize(number)), number)
+}
+
+// AppendVarInt appends the varint equivalent of number to dst and returns the extended slice.
+//
+// Unlike WriteVarInt, nothing is allocated if dst has enough capacity, which helps when building many packets into a reused buffer.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+func AppendVarInt(dst []byte, number int) []byte {
+	value := uint32(int32(number))
+
+	for {
+		// No more bytes in the varint.
+		if value&0xFFFFFF80 == 0 {
+			return append(dst, byte(value&0x7F))
+		}
+
+		dst = append(dst, byte((value&0x7F)|0x80))
+		value >>= 7
+	}
+}
+
+// varIntSize returns the number of bytes the varint equivalent of number is made up of.
+func varIntSize(number int) int {
+	value := uint32(int32(number))
+
+	size := 1
+	for value&0xFFFFFF80 != 0 {
+		value >>= 7
+		size++
+	}
+
+	return size
+}
+
+// ReadVarInt converts a varint into its int equivalent.
+//
+// The varint is decoded as a 32-bit two's complement integer, so 5 byte varints can decode to negative numbers.
+// ErrLargeVarInt is returned as soon as the 5th byte signals that another byte follows,
+// and io.ErrUnexpectedEOF is returned if varInt is empty or ends while another byte is still signaled.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+func ReadVarInt(varInt []byte) (int, error) {
+	var value uint32
+	bitOffSet := 0
+
+	for i, currentByte := range varInt {
+		value |= uint32(currentByte&0x7F) << bitOffSet
+
+		if currentByte&0x80 == 0 {
+			return int(int32(value)), nil
+		}
+
+		// The 5th byte can't be followed by another byte.
+		if i == maxVarIntSize-1 {
+			return -1, ErrLargeVarInt
+		}
+		bitOffSet += 7
+	}
+
+	// varInt ended without a byte terminating the varint.
+	return -1, io.ErrUnexpectedEOF
+}
+
+// ReadVarIntFrom reads a varint from r and converts it into its int equivalent.
+//
+// If r doesn't implement io.ByteReader, bytes are read one at a time so nothing past the end of the varint is consumed from r.
+// ErrLargeVarInt is returned as soon as the varint exceeds the 5 bytes size limit.
+// https://wiki.vg/Protocol#VarInt_and_VarLong
+func ReadVarIntFrom(r io.Reader) (int, error) {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = singleByteReader{r}
+	}
+
+	return readVarIntFrom(byteReader)
+}
+
+// readVarIntFrom reads a varint from r and converts it into its int equivalent.
+func readVarIntFrom(r io.ByteReader) (int, error) {
+	varInt := []byte{}
+
+	for {
+		if len(varInt) == maxVarIntSize {
+			return -1, ErrLargeVarInt
+		}
+
+		currentByte, err := r.ReadByte()
+		if err != nil {
+			return -1, err
+		}
+		varInt = append(varInt, currentByte)
+
+		// Varint has terminated.
+		if currentByte&0x80 == 0 {
+			break
+		}
+	}
+
+	return ReadVarInt(varInt)
+}
+
+// singleByteReader implements io.ByteReader over an io.Reader by reading one byte at a time.
+type singleByteReader struct {
+	r io.Reader
+}
+
+// ReadByte reads a single byte from the underlying io.Reader.
+func (s singleByteReader) ReadByte() (byte, error) {
+	recvBuffer := make([]byte, 1)
+
+	_, err := io.ReadFull(s.r, recvBuffer)
+	if err != nil {
+		return 0, err
+	}
+
+	return recvBuffer[0], nil
+}
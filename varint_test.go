@@ -0,0 +1,73 @@
+package mcstatusgo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReadVarInt(t *testing.T) {
+	tests := []struct {
+		varInt []byte
+		want   int
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x7F}, 127},
+		{[]byte{0x80, 0x01}, 128},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x07}, 2147483647},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, -1},
+		// Bytes after the end of the varint are ignored.
+		{[]byte{0x01, 0x80}, 1},
+	}
+
+	for _, test := range tests {
+		got, err := ReadVarInt(test.varInt)
+		if err != nil {
+			t.Errorf("ReadVarInt(% x): %v", test.varInt, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ReadVarInt(% x) = %d, want %d", test.varInt, got, test.want)
+		}
+	}
+}
+
+func TestReadVarIntInvalid(t *testing.T) {
+	tests := []struct {
+		varInt []byte
+		want   error
+	}{
+		{[]byte{}, io.ErrUnexpectedEOF},
+		{[]byte{0x80}, io.ErrUnexpectedEOF},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF}, io.ErrUnexpectedEOF},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, ErrLargeVarInt},
+	}
+
+	for _, test := range tests {
+		if _, err := ReadVarInt(test.varInt); !errors.Is(err, test.want) {
+			t.Errorf("ReadVarInt(% x) error = %v, want %v", test.varInt, err, test.want)
+		}
+	}
+}
+
+func TestReadVarIntFromTruncated(t *testing.T) {
+	_, err := ReadVarIntFrom(bytes.NewReader([]byte{0x80}))
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("ReadVarIntFrom() error = %v, want io.EOF", err)
+	}
+}
+
+func TestVarIntRoundTrip(t *testing.T) {
+	for _, number := range []int{0, 1, 127, 128, 255, 25565, 2097151, 2147483647, -1, -2147483648} {
+		varInt := WriteVarInt(number)
+		if len(varInt) != varIntSize(number) {
+			t.Errorf("WriteVarInt(%d) is %d bytes, want %d", number, len(varInt), varIntSize(number))
+		}
+
+		got, err := ReadVarInt(varInt)
+		if err != nil || got != number {
+			t.Errorf("ReadVarInt(WriteVarInt(%d)) = %d, %v", number, got, err)
+		}
+	}
+}